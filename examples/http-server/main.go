@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"mini-jupiter/pkg/config"
@@ -16,17 +21,23 @@ import (
 	"mini-jupiter/pkg/ratelimiter"
 	"mini-jupiter/pkg/runtime"
 
+	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 )
 
 type AppConfig struct {
 	App struct {
-		Name string `mapstructure:"name" yaml:"name"`
-		Env  string `mapstructure:"env" yaml:"env"`
+		Name string `mapstructure:"name" yaml:"name" validate:"required"`
+		Env  string `mapstructure:"env" yaml:"env" default:"dev"`
 	} `mapstructure:"app" yaml:"app"`
 	HTTP struct {
-		Addr string `mapstructure:"addr" yaml:"addr"`
+		Addr string `mapstructure:"addr" yaml:"addr" default:":8080" validate:"required"`
 	} `mapstructure:"http" yaml:"http"`
+	// Admin 管理接口（/admin/*）使用独立监听地址，默认只监听本机；设置 Token 后需携带 Bearer token
+	Admin struct {
+		Addr  string        `mapstructure:"addr" yaml:"addr" default:"127.0.0.1:8081" validate:"required"`
+		Token config.Secret `mapstructure:"token" yaml:"token"`
+	} `mapstructure:"admin" yaml:"admin"`
 	Log applog.Config `mapstructure:"log" yaml:"log"`
 	Metric metric.Config `mapstructure:"metric" yaml:"metric"`
 	RateLimit ratelimiter.Config `mapstructure:"ratelimit" yaml:"ratelimit"`
@@ -38,6 +49,28 @@ type AppConfig struct {
 	} `mapstructure:"middleware" yaml:"middleware"`
 }
 
+// 业务错误码在包级变量中登记，HTTP 状态码与可重试性由登记表决定
+var (
+	codeUserNotFound = apperr.MustRegister(apperr.CodeInfo{
+		Code:    10404,
+		Message: "user {id} not found",
+		Status:  apperr.StatusNotFound,
+	})
+	codeJobQueueUnavailable = apperr.MustRegister(apperr.CodeInfo{
+		Code:      10503,
+		Message:   "job queue unavailable",
+		Status:    apperr.StatusUnavailable,
+		Retryable: true,
+	})
+)
+
+func init() {
+	apperr.RegisterMessages("zh", map[int]string{
+		codeUserNotFound:        "用户 {id} 不存在",
+		codeJobQueueUnavailable: "任务队列暂不可用，请稍后重试",
+	})
+}
+
 func main() {
 	//加载配置
 	var cfg AppConfig
@@ -45,13 +78,67 @@ func main() {
 	if configPath == "" {
 		configPath = "examples/http-server/config.yaml"
 	}
-	_, err := config.Load(
+	flags := pflag.NewFlagSet(os.Args[0], pflag.ExitOnError)
+	flags.String("http.addr", "", "override http.addr")
+	flags.String("log.level", "", "override log.level")
+	printConfig := flags.Bool("print-config", false, "print effective config and exit")
+	printConfigDoc := flags.Bool("print-config-doc", false, "print config reference (markdown) and exit")
+	printSchema := flags.Bool("print-config-schema", false, "print config JSON Schema and exit")
+	validateOnly := flags.Bool("validate-config", false, "validate the config file and exit")
+	_ = flags.Parse(os.Args[1:])
+	if *printConfigDoc {
+		if err := config.WriteReference(os.Stdout, &cfg, ""); err != nil {
+			panic(err)
+		}
+		return
+	}
+	if *printSchema {
+		schema, err := config.JSONSchema(&cfg)
+		if err != nil {
+			panic(err)
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(schema)
+		return
+	}
+	if *validateOnly {
+		if err := config.ValidateFile[AppConfig](configPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("config ok:", configPath)
+		return
+	}
+	ext := filepath.Ext(configPath)
+	//优先级：config.yaml < config.local.yaml < 远程配置（CONFIG_URL） < 环境变量 < 命令行
+	sources := []config.Source{config.OptionalFile(strings.TrimSuffix(configPath, ext) + ".local" + ext)}
+	if url := os.Getenv("CONFIG_URL"); url != "" {
+		sources = append(sources, config.HTTP(url))
+	}
+	sources = append(sources, config.Env(""), config.Flags(flags))
+	cfgMgr, err := config.Load(
 		configPath,
 		&cfg,
-		config.WithWatch(),
-		config.WithOnChange(func(newCfg any) {
-			c := newCfg.(*AppConfig)
-			applog.L(context.Background()).Info("config reloaded",
+		config.WithSources[AppConfig](sources...),
+		config.WithWatch[AppConfig](),
+		config.WithParticipant[AppConfig](config.ParticipantFuncs[AppConfig]{
+			ID: "http",
+			PrepareFunc: func(old, new *AppConfig) error {
+				if old.HTTP.Addr != new.HTTP.Addr {
+					return errors.New("http.addr cannot be changed without restart")
+				}
+				if old.Admin.Addr != new.Admin.Addr {
+					return errors.New("admin.addr cannot be changed without restart")
+				}
+				if old.Admin.Token != "" && new.Admin.Token == "" {
+					return errors.New("admin.token cannot be removed without restart")
+				}
+				return nil
+			},
+		}),
+		config.WithOnChange(func(_, c *AppConfig) {
+			applog.L(context.Background()).Info("config applied",
 				zap.String("app", c.App.Name),
 				zap.String("env", c.App.Env),
 				zap.String("addr", c.HTTP.Addr),
@@ -62,10 +149,35 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	defer cfgMgr.Close()
+	if *printConfig {
+		out, err := cfgMgr.Dump("yaml")
+		if err != nil {
+			panic(err)
+		}
+		_, _ = os.Stdout.Write(out)
+		return
+	}
 	if err := applog.Init(cfg.Log); err != nil {
 		panic(err)
 	}
 	defer applog.Sync()
+	// 配合外部 logrotate：kill -HUP 后重新打开日志文件
+	applog.ReopenOnSignal(context.Background())
+	if _, err := config.SubscribeValue(cfgMgr, "log.level", func(_, level string) {
+		if err := applog.SetLevel(level); err != nil {
+			applog.L(context.Background()).Error("apply log level failed", zap.Error(err))
+		}
+	}); err != nil {
+		panic(err)
+	}
+	if _, err := config.SubscribeValue(cfgMgr, "log.modules", func(_, modules map[string]string) {
+		if err := applog.SetModuleLevels(modules); err != nil {
+			applog.L(context.Background()).Error("apply module log levels failed", zap.Error(err))
+		}
+	}); err != nil {
+		panic(err)
+	}
 
 	//注册路由
 	mux := http.NewServeMux()
@@ -86,7 +198,8 @@ func main() {
 			apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeBadRequest, "method not allowed"))
 			return
 		}
-		apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeNotFound, "user not found"))
+		err := apperr.New(codeUserNotFound, "").WithParam("id", r.URL.Query().Get("id"))
+		apperr.WriteHTTPWithContext(r.Context(), w, err)
 	})
 	wp := pool.New(4, pool.WithBuffer(128), pool.WithTaskTimeout(3*time.Second))
 	mux.HandleFunc("/jobs", func(w http.ResponseWriter, r *http.Request) {
@@ -104,7 +217,7 @@ func main() {
 				return ctx.Err()
 			}
 		}); err != nil {
-			apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeInternalError, "submit failed"))
+			apperr.WriteHTTPWithContext(r.Context(), w, apperr.Wrap(codeJobQueueUnavailable, "", err))
 			return
 		}
 		w.WriteHeader(http.StatusAccepted)
@@ -116,15 +229,27 @@ func main() {
 		metrics = metric.New(cfg.Metric)
 		mux.Handle(cfg.Metric.Path, metrics.Handler())
 		apperr.SetReporter(metrics.ObserveError)
+		applog.SetDropReporter(metrics.ObserveLogDropped)
+		cfgMgr.SetObserver(metrics)
 	}
 
 	var limiter *ratelimiter.Limiter
 	if cfg.RateLimit.Enabled {
 		limiter = ratelimiter.New(cfg.RateLimit.Rate, cfg.RateLimit.Burst)
+		if _, err := config.SubscribeValue(cfgMgr, "ratelimit", func(_, c ratelimiter.Config) {
+			limiter.Update(c.Rate, c.Burst)
+		}); err != nil {
+			panic(err)
+		}
 	}
 	var isoMgr *isolation.Manager
 	if cfg.Isolation.Enabled {
 		isoMgr = isolation.NewManager(cfg.Isolation)
+		if _, err := config.SubscribeValue(cfgMgr, "isolation", func(_, c isolation.Config) {
+			isoMgr.Update(c)
+		}); err != nil {
+			panic(err)
+		}
 	}
 
 	var middlewares []middleware.Middleware
@@ -134,6 +259,14 @@ func main() {
 	if cfg.Middleware.TraceID {
 		middlewares = append(middlewares, middleware.TraceID())
 	}
+	middlewares = append(middlewares, middleware.Locale())
+	middlewares = append(middlewares, middleware.LogFields(func(r *http.Request) []zap.Field {
+		fields := []zap.Field{zap.String("route", r.URL.Path)}
+		if tenant := r.Header.Get("X-Tenant-Id"); tenant != "" {
+			fields = append(fields, zap.String("tenant", tenant))
+		}
+		return fields
+	}))
 	if isoMgr != nil {
 		middlewares = append(middlewares, middleware.Isolation(isoMgr))
 	}
@@ -149,15 +282,33 @@ func main() {
 		Addr:    cfg.HTTP.Addr,
 		Handler: handler,
 	}
+
+	// 管理接口不挂在业务端口上：可修改日志级别、查看配置与最近日志，只应对运维开放
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/admin/config", cfgMgr.Handler())
+	adminMux.Handle("/admin/log/level", applog.LevelHandler())
+	adminMux.Handle("/admin/log/recent", applog.RingHandler())
+	adminMiddlewares := []middleware.Middleware{middleware.Recovery()}
+	if cfg.Admin.Token != "" {
+		adminMiddlewares = append(adminMiddlewares, middleware.BearerAuth(func() string {
+			return cfgMgr.Current().Admin.Token.Value()
+		}))
+	}
+	adminServer := &http.Server{
+		Addr:    cfg.Admin.Addr,
+		Handler: middleware.Chain(adminMiddlewares...)(adminMux),
+	}
 	//组件注册
 	app := runtime.NewWithOptions(runtime.WithStopTimeout(8 * time.Second))
-	app.Use(&httpComponent{server: server}, &poolComponent{pool: wp})
+	app.Use(&httpComponent{server: server}, &httpComponent{server: adminServer}, &poolComponent{pool: wp})
 	//启动app
 	if err := app.Start(context.Background()); err != nil {
 		applog.L(context.Background()).Fatal("app start failed", zap.Error(err))
 	}
 	applog.L(context.Background()).Info("http server listening",
 		zap.String("addr", cfg.HTTP.Addr),
+		zap.String("admin_addr", cfg.Admin.Addr),
+		zap.Bool("admin_auth", cfg.Admin.Token != ""),
 	)
 
 	_ = runtime.WaitSignal(context.Background())
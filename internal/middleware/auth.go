@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	apperr "mini-jupiter/pkg/errors"
+)
+
+// BearerAuth 要求请求携带 Authorization: Bearer <token>；token 在每次请求时读取以支持热更新，
+// 返回空字符串时拒绝所有请求
+func BearerAuth(token func() string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			want := token()
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || want == "" || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeUnauthorized, ""))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBearerAuth(t *testing.T) {
+	token := "s3cret"
+	h := BearerAuth(func() string { return token })(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusNoContent},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"wrong scheme", "s3cret", "Basic s3cret", http.StatusUnauthorized},
+		{"rotated token", "n3w", "Bearer s3cret", http.StatusUnauthorized},
+		{"empty token rejects everything", "", "Bearer ", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token = tt.token
+			req := httptest.NewRequest(http.MethodPut, "/admin/log/level", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
+)
+
+// LogFields 从请求中提取字段（用户、租户、路由等）附加到 ctx，下游 applog.L(ctx) 的日志都会携带
+func LogFields(extract func(r *http.Request) []zap.Field) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fields := extract(r); len(fields) > 0 {
+				r = r.WithContext(applog.WithFields(r.Context(), fields...))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
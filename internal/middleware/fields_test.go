@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	applog "mini-jupiter/pkg/log"
+	"mini-jupiter/pkg/log/logtest"
+
+	"go.uber.org/zap"
+)
+
+func TestLogFields(t *testing.T) {
+	logs := logtest.New(t)
+	h := Chain(TraceID(), LogFields(func(r *http.Request) []zap.Field {
+		return []zap.Field{zap.String("route", r.URL.Path), zap.String("tenant", r.Header.Get("X-Tenant"))}
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		applog.L(r.Context()).Info("handled")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("X-Tenant", "acme")
+	req.Header.Set("X-Trace-Id", "abc")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	logs.AssertLogged(logtest.Message("handled"), logtest.TraceID("abc"), logtest.Field("route", "/ping"), logtest.Field("tenant", "acme"))
+}
@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"time"
 
 	apperr "mini-jupiter/pkg/errors"
 	"mini-jupiter/pkg/isolation"
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
 )
 
 func Isolation(mgr *isolation.Manager) Middleware {
@@ -21,6 +25,10 @@ func Isolation(mgr *isolation.Manager) Middleware {
 			}
 			release, err := limiter.Acquire(r.Context())
 			if err != nil {
+				applog.Limited(r.Context(), time.Second).Warn("request rejected by isolation",
+					zap.String("path", r.URL.Path),
+					zap.Error(err),
+				)
 				apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeTooManyRequests, "request rejected"))
 				return
 			}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	apperr "mini-jupiter/pkg/errors"
+)
+
+// Locale 按 Accept-Language 协商语言并保存到 ctx，错误响应据此输出本地化消息
+func Locale() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Language")
+			if locale := apperr.NegotiateLocale(r.Header.Get("Accept-Language")); locale != "" {
+				r = r.WithContext(apperr.WithLocale(r.Context(), locale))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,16 +2,23 @@ package middleware
 
 import (
 	"net/http"
+	"time"
 
 	apperr "mini-jupiter/pkg/errors"
+	applog "mini-jupiter/pkg/log"
 	"mini-jupiter/pkg/ratelimiter"
+
+	"go.uber.org/zap"
 )
 
 func RateLimit(l *ratelimiter.Limiter) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if l != nil && !l.Allow() {
-				apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeTooManyRequests, "too many requests"))
+				applog.Limited(r.Context(), time.Second).Warn("request rate limited",
+					zap.String("path", r.URL.Path),
+				)
+				apperr.WriteHTTPWithContext(r.Context(), w, apperr.New(apperr.CodeTooManyRequests, ""))
 				return
 			}
 			next.ServeHTTP(w, r)
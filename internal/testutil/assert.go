@@ -0,0 +1,15 @@
+// Package testutil 提供各包测试共用的断言辅助函数
+package testutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Equal 以 reflect.DeepEqual 比较 got 与 want，不相等时报告错误并继续执行
+func Equal[V any](t testing.TB, got, want V) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -1,73 +1,144 @@
 package config
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
 	"sync/atomic"
 
-	"github.com/fsnotify/fsnotify"
+	applog "mini-jupiter/pkg/log"
+
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
-type OnChangeFunc func(cfg any)
+type OnChangeFunc[T any] func(old, new *T)
+
+type ErrorFunc func(err error)
+
+// SubscribeFunc 接收订阅 key 对应的新旧值，值类型与配置结构体中该字段的类型一致
+type SubscribeFunc func(old, new any)
+
+type subscription struct {
+	id  uint64
+	key string
+	fn  SubscribeFunc
+}
+
+// ReloadObserver 接收加载结果，通常由 metric.Metrics 实现
+type ReloadObserver interface {
+	ObserveConfigReload(success bool, hash string)
+}
 
-type Option func(*Manager)
+type Option[T any] func(*Manager[T])
 
-type Manager struct {
-	v           *viper.Viper
+// snapshot 是与当前配置一同生效的元信息，只在重载提交成功后替换
+type snapshot struct {
+	hash    string
+	origins map[string]string
+	secrets map[string]bool
+}
+
+type Manager[T any] struct {
 	cfgType     reflect.Type
-	current     atomic.Value
+	current     atomic.Pointer[T]
 	mu          sync.Mutex
-	onChange    []OnChangeFunc
+	reloadMu    sync.Mutex
+	cancel      context.CancelFunc
+	watchers    sync.WaitGroup
+	onChange    []OnChangeFunc[T]
+	onError     []ErrorFunc
+	observer    atomic.Value
+	meta        atomic.Pointer[snapshot]
+	partMu      sync.RWMutex
+	parts       []Participant[T]
+	resolvers   map[string]SecretResolver
+	sources     []Source
+	layers      []Source
+	subMu       sync.RWMutex
+	subs        []subscription
+	nextSubID   uint64
 	envPrefix   string
 	enableWatch bool
 }
 
-func WithEnvPrefix(prefix string) Option {
-	return func(m *Manager) {
+func WithEnvPrefix[T any](prefix string) Option[T] {
+	return func(m *Manager[T]) {
 		m.envPrefix = prefix
 	}
 }
 
-func WithOnChange(fn OnChangeFunc) Option {
-	return func(m *Manager) {
+// WithSources 在基础文件之后按顺序叠加配置来源，后者优先级更高；
+// 设置后不再自动追加环境变量层，需显式传入 Env
+func WithSources[T any](sources ...Source) Option[T] {
+	return func(m *Manager[T]) {
+		m.sources = append(m.sources, sources...)
+	}
+}
+
+// WithSecretResolver 注册 ${scheme:ref} 引用的解析器，内置 env 与 file
+func WithSecretResolver[T any](scheme string, r SecretResolver) Option[T] {
+	return func(m *Manager[T]) {
+		if r != nil {
+			m.resolvers[scheme] = r
+		}
+	}
+}
+
+func WithOnChange[T any](fn OnChangeFunc[T]) Option[T] {
+	return func(m *Manager[T]) {
 		if fn != nil {
 			m.onChange = append(m.onChange, fn)
 		}
 	}
 }
 
-func WithWatch() Option {
-	return func(m *Manager) {
+// WithOnReloadError 注册热更新失败回调，失败时保留旧配置
+func WithOnReloadError[T any](fn ErrorFunc) Option[T] {
+	return func(m *Manager[T]) {
+		if fn != nil {
+			m.onError = append(m.onError, fn)
+		}
+	}
+}
+
+func WithWatch[T any]() Option[T] {
+	return func(m *Manager[T]) {
 		m.enableWatch = true
 	}
 }
 
-func Load(path string, cfg any, opts ...Option) (*Manager, error) {
+// Load 读取配置到 cfg，cfg 同时作为 Current() 的初始快照
+func Load[T any](path string, cfg *T, opts ...Option[T]) (*Manager[T], error) {
 	cfgType, err := validateConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	m := &Manager{
-		v:       viper.New(),
-		cfgType: cfgType,
+	m := &Manager[T]{
+		cfgType:   cfgType,
+		resolvers: defaultResolvers(),
 	}
 	for _, exe := range opts {
 		exe(m)
 	}
 
-	if err := m.initViper(path); err != nil {
+	if err := m.initLayers(path); err != nil {
 		return nil, err
 	}
-	if err := m.reloadInto(cfg); err != nil {
+	meta, err := m.reloadInto(cfg)
+	if err != nil {
 		return nil, err
 	}
 	m.current.Store(cfg)
+	m.meta.Store(meta)
 
 	if m.enableWatch {
 		m.watch()
@@ -75,61 +146,275 @@ func Load(path string, cfg any, opts ...Option) (*Manager, error) {
 	return m, nil
 }
 
-func (m *Manager) Current() any {
+func (m *Manager[T]) Current() *T {
 	return m.current.Load()
 }
 
-func (m *Manager) watch() {
-	m.v.WatchConfig()
-	m.v.OnConfigChange(func(_ fsnotify.Event) {
-		cfg := reflect.New(m.cfgType).Interface()
-		if err := m.reloadInto(cfg); err != nil {
-			return
-		}
-		m.current.Store(cfg)
-		for _, fn := range m.onChange {
-			fn(cfg)
+// Hash 返回当前生效配置的摘要，可用于比对各实例配置是否一致；密钥的值不参与计算
+func (m *Manager[T]) Hash() string {
+	if meta := m.meta.Load(); meta != nil {
+		return meta.hash
+	}
+	return ""
+}
+
+// SetObserver 设置加载结果观察者，并立即上报当前配置（初始加载计为一次成功）
+func (m *Manager[T]) SetObserver(o ReloadObserver) {
+	if o == nil {
+		return
+	}
+	m.observer.Store(o)
+	o.ObserveConfigReload(true, m.Hash())
+}
+
+// Subscribe 订阅某个 key（如 "ratelimit"、"isolation.routes"）的变更，
+// 仅当该 key 及其子 key 在重载前后不一致时回调，返回取消订阅函数；key 不区分大小写
+func (m *Manager[T]) Subscribe(key string, fn SubscribeFunc) (func(), error) {
+	if fn == nil {
+		return nil, errors.New("subscribe func is nil")
+	}
+	_, key, err := resolveKey(m.cfgType, key)
+	if err != nil {
+		return nil, err
+	}
+	m.subMu.Lock()
+	defer m.subMu.Unlock()
+	m.nextSubID++
+	id := m.nextSubID
+	m.subs = append(m.subs, subscription{id: id, key: key, fn: fn})
+	return func() {
+		m.subMu.Lock()
+		defer m.subMu.Unlock()
+		for i, s := range m.subs {
+			if s.id == id {
+				m.subs = append(m.subs[:i], m.subs[i+1:]...)
+				return
+			}
 		}
+	}, nil
+}
+
+// SubscribeValue 是 Subscribe 的强类型版本，订阅时即校验 V 与 key 对应字段类型一致
+func SubscribeValue[T, V any](m *Manager[T], key string, fn func(old, new V)) (func(), error) {
+	if fn == nil {
+		return nil, errors.New("subscribe func is nil")
+	}
+	t, err := typeAt(m.cfgType, key)
+	if err != nil {
+		return nil, err
+	}
+	if want := reflect.TypeOf((*V)(nil)).Elem(); t != want {
+		return nil, fmt.Errorf("config key %q is %s, not %s", key, t, want)
+	}
+	return m.Subscribe(key, func(old, new any) {
+		o, _ := old.(V)
+		n, _ := new.(V)
+		fn(o, n)
 	})
 }
 
-func (m *Manager) reloadInto(cfg any) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	if err := m.v.ReadInConfig(); err != nil {
-		return fmt.Errorf("read config: %w", err)
+// Origin 返回叶子 key（如 "http.addr"）在当前配置中生效值的来源名称
+func (m *Manager[T]) Origin(key string) string {
+	if meta := m.meta.Load(); meta != nil {
+		return meta.origins[strings.ToLower(key)]
 	}
-	if err := m.v.Unmarshal(cfg); err != nil {
-		return fmt.Errorf("unmarshal config: %w", err)
+	return ""
+}
+
+// Redacted 返回展开为叶子 key 的当前配置，密钥类的值已替换为掩码，可安全用于日志
+func (m *Manager[T]) Redacted() map[string]any {
+	return redactedTree(m.Current(), m.secretKeys())
+}
+
+func (m *Manager[T]) secretKeys() map[string]bool {
+	if meta := m.meta.Load(); meta != nil {
+		return meta.secrets
 	}
 	return nil
 }
 
-func (m *Manager) initViper(path string) error {
-	if path == "" {
-		return errors.New("config path is empty")
+func (m *Manager[T]) Origins() map[string]string {
+	out := make(map[string]string)
+	if meta := m.meta.Load(); meta != nil {
+		for k, v := range meta.origins {
+			out[k] = v
+		}
 	}
-	m.v.SetConfigFile(path)
+	return out
+}
 
-	ext := strings.TrimPrefix(filepath.Ext(path), ".")
-	if ext != "" {
-		m.v.SetConfigType(ext)
+// Close 停止所有 Provider 的监听
+func (m *Manager[T]) Close() {
+	if m.cancel != nil {
+		m.cancel()
 	}
+	m.watchers.Wait()
+}
 
-	if m.envPrefix != "" {
-		m.v.SetEnvPrefix(m.envPrefix)
+// watch 监听所有实现了 Provider 的配置层，任一层变更都会重新合并全部层
+func (m *Manager[T]) watch() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	for _, src := range m.layers {
+		p, ok := src.(Provider)
+		if !ok {
+			continue
+		}
+		m.watchers.Add(1)
+		go func() {
+			defer m.watchers.Done()
+			if err := p.Watch(ctx, m.reload); err != nil {
+				applog.NamedL(ctx, "config").Error("config watch stopped",
+					zap.String("source", p.Name()),
+					zap.Error(err),
+				)
+			}
+		}()
 	}
-	m.v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	m.v.AutomaticEnv()
+}
 
-	if err := bindEnvs(m.v, m.cfgType, ""); err != nil {
-		return err
+func (m *Manager[T]) reload(source string) {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+	cfg := new(T)
+	meta, err := m.reloadInto(cfg)
+	if err != nil {
+		m.reloadFailed(source, err)
+		return
+	}
+	old := m.current.Load()
+	changes := Diff(old, cfg)
+	if len(changes) > 0 {
+		if err := m.apply(old, cfg); err != nil {
+			m.reloadFailed(source, err)
+			return
+		}
+	}
+	m.current.Store(cfg)
+	m.meta.Store(meta)
+	keys := make([]string, 0, len(changes))
+	for _, c := range changes {
+		keys = append(keys, c.Key)
+	}
+	applog.Named("config").Info("config reloaded",
+		zap.String("source", source),
+		zap.String("hash", meta.hash),
+		zap.Strings("changed_keys", keys),
+	)
+	m.observe(true, meta.hash)
+	if len(changes) == 0 {
+		return
+	}
+	for _, fn := range m.onChange {
+		fn(old, cfg)
+	}
+	m.notify(old, cfg, changes)
+}
+
+func (m *Manager[T]) reloadFailed(source string, err error) {
+	applog.Named("config").Error("config reload failed, keep previous config",
+		zap.String("source", source),
+		zap.String("hash", m.Hash()),
+		zap.Error(err),
+	)
+	m.observe(false, m.Hash())
+	for _, fn := range m.onError {
+		fn(err)
+	}
+}
+
+func (m *Manager[T]) notify(old, cfg *T, changes []Change) {
+	m.subMu.RLock()
+	subs := append([]subscription(nil), m.subs...)
+	m.subMu.RUnlock()
+	for _, s := range subs {
+		for _, c := range changes {
+			if keyMatches(s.key, c.Key) {
+				s.fn(valueAt(old, s.key), valueAt(cfg, s.key))
+				break
+			}
+		}
+	}
+}
+
+func (m *Manager[T]) observe(success bool, hash string) {
+	if o, ok := m.observer.Load().(ReloadObserver); ok {
+		o.ObserveConfigReload(success, hash)
+	}
+}
+
+func (m *Manager[T]) reloadInto(cfg *T) (*snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings, origins, err := mergeLayers(m.layers)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	applyDefaults(settings, origins, m.cfgType, "")
+	secrets, err := resolveSecrets(settings, m.resolvers)
+	if err != nil {
+		return nil, err
+	}
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("merge config: %w", err)
+	}
+	if err := v.Unmarshal(cfg, viper.DecodeHook(decodeHook())); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	if err := validateStruct(cfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	// 摘要会对外暴露（X-Config-Hash、config_info 指标），只对掩码后的配置计算，避免离线猜解密钥
+	hash, err := settingsHash(redactedTree(cfg, secrets))
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{hash: hash, origins: origins, secrets: secrets}, nil
+}
+
+// decodeHook 在 viper 默认的 duration/逗号切片转换之外支持 TextUnmarshaler（如 Size）
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+func settingsHash(settings map[string]any) (string, error) {
+	// encoding/json 对 map 按 key 排序，保证同一配置得到同一摘要
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return "", fmt.Errorf("hash config: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// initLayers 组装配置层：基础文件 < WithSources（默认为环境变量）
+func (m *Manager[T]) initLayers(path string) error {
+	if path == "" && len(m.sources) == 0 {
+		return errors.New("config path is empty")
+	}
+	if path != "" {
+		m.layers = append(m.layers, File(path))
+	}
+	if len(m.sources) == 0 {
+		m.layers = append(m.layers, Env(m.envPrefix))
+	} else {
+		m.layers = append(m.layers, m.sources...)
+	}
+	for _, src := range m.layers {
+		if b, ok := src.(typeBinder); ok {
+			b.bindType(m.cfgType)
+		}
 	}
 	return nil
 }
 
 // 配置错误检查
-func validateConfig(cfg any) (reflect.Type, error) {
+func validateConfig[T any](cfg *T) (reflect.Type, error) {
 	if cfg == nil {
 		return nil, errors.New("cfg is nil")
 	}
@@ -145,6 +430,16 @@ func validateConfig(cfg any) (reflect.Type, error) {
 }
 
 func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
+	return walkLeaves(t, prefix, func(key string, _ reflect.StructField) error {
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("bind env %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+// walkLeaves 递归遍历结构体字段，对每个非结构体字段（叶子）以点分 key 调用 fn
+func walkLeaves(t reflect.Type, prefix string, fn func(key string, field reflect.StructField) error) error {
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		if field.PkgPath != "" {
@@ -163,18 +458,26 @@ func bindEnvs(v *viper.Viper, t reflect.Type, prefix string) error {
 			ft = ft.Elem()
 		}
 		if ft.Kind() == reflect.Struct {
-			if err := bindEnvs(v, ft, key); err != nil {
+			if err := walkLeaves(ft, key, fn); err != nil {
 				return err
 			}
 			continue
 		}
-		if err := v.BindEnv(key); err != nil {
-			return fmt.Errorf("bind env %s: %w", key, err)
+		if err := fn(key, field); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix != "" {
+		name = strings.ToUpper(prefix) + "_" + name
+	}
+	return name
+}
+
 func fieldKey(field reflect.StructField) (string, bool) {
 	if tag := field.Tag.Get("mapstructure"); tag != "" {
 		if tag == "-" {
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+type observation struct {
+	success bool
+	hash    string
+}
+
+type recordingObserver struct {
+	mu  sync.Mutex
+	obs []observation
+}
+
+func (o *recordingObserver) ObserveConfigReload(success bool, hash string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.obs = append(o.obs, observation{success: success, hash: hash})
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	var cfg appConfig
+	_, err := Load(exampleConfig, &cfg, WithSources[appConfig](Map("test", map[string]any{"app.name": ""})))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load error = %v, want *ValidationError", err)
+	}
+	testutil.Equal(t, verr.Errors, []FieldError{{Field: "app.name", Rule: "required", Err: "is required"}})
+
+	if _, err := Load[appConfig]("", nil); err == nil {
+		t.Error("Load accepted a nil config")
+	}
+	if _, err := Load("", &cfg); err == nil {
+		t.Error("Load accepted an empty path without sources")
+	}
+}
+
+func TestReloadKeepsPreviousConfigOnError(t *testing.T) {
+	values := map[string]any{"ratelimit.rate": 2}
+	var (
+		cfg      appConfig
+		errs     []error
+		changes  int
+		observer recordingObserver
+	)
+	m, err := Load(exampleConfig, &cfg,
+		WithSources[appConfig](Map("test", values)),
+		WithOnReloadError[appConfig](func(err error) { errs = append(errs, err) }),
+		WithOnChange(func(old, new *appConfig) {
+			changes++
+			testutil.Equal(t, old.RateLimit.Rate, 2.0)
+			testutil.Equal(t, new.RateLimit.Rate, 3.0)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	m.SetObserver(&observer)
+	initial := m.Current()
+	hash := m.Hash()
+
+	values["app.name"] = ""
+	m.reload("test")
+	if m.Current() != initial || m.Hash() != hash {
+		t.Fatal("invalid reload replaced the current config")
+	}
+	if len(errs) != 1 {
+		t.Fatalf("got %d reload errors, want 1", len(errs))
+	}
+	var verr *ValidationError
+	if !errors.As(errs[0], &verr) {
+		t.Errorf("reload error = %v, want *ValidationError", errs[0])
+	}
+
+	delete(values, "app.name")
+	values["ratelimit.rate"] = 3
+	m.reload("test")
+	testutil.Equal(t, m.Current().RateLimit.Rate, 3.0)
+	testutil.Equal(t, changes, 1)
+	if m.Hash() == hash {
+		t.Error("hash unchanged after a successful reload")
+	}
+
+	// 无变更的重载不触发 OnChange
+	m.reload("test")
+	testutil.Equal(t, changes, 1)
+
+	testutil.Equal(t, observer.obs, []observation{
+		{success: true, hash: hash},
+		{success: false, hash: hash},
+		{success: true, hash: m.Hash()},
+		{success: true, hash: m.Hash()},
+	})
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// applyDefaults 将 default 标签中的值补入 settings 中缺失的 key（在 unmarshal 之前），
+// map 类型字段（如 isolation.routes）的每个已配置条目也会按元素结构体补齐
+func applyDefaults(settings map[string]any, origins map[string]string, t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, skip := fieldKey(field)
+		if skip {
+			continue
+		}
+		name = strings.ToLower(name)
+		key := joinKey(prefix, name)
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			child, ok := settings[name].(map[string]any)
+			if !ok {
+				if _, set := settings[name]; set {
+					continue
+				}
+				child = make(map[string]any)
+			}
+			applyDefaults(child, origins, ft, key)
+			if len(child) > 0 {
+				settings[name] = child
+			}
+		case ft.Kind() == reflect.Map && structElem(ft) != nil:
+			entries, ok := settings[name].(map[string]any)
+			if !ok {
+				break
+			}
+			for entryKey, entry := range entries {
+				if em, ok := entry.(map[string]any); ok {
+					applyDefaults(em, origins, structElem(ft), joinKey(key, entryKey))
+				}
+			}
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if _, set := settings[name]; !set {
+				settings[name] = def
+				origins[key] = originDefault
+			}
+		}
+	}
+}
+
+func structElem(t reflect.Type) reflect.Type {
+	et := t.Elem()
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return nil
+	}
+	return et
+}
+
+// WriteReference 以 Markdown 表格输出 cfg（结构体指针）的全部配置项：key、环境变量、类型、默认值与校验规则
+func WriteReference(w io.Writer, cfg any, envPrefix string) error {
+	t := reflect.TypeOf(cfg)
+	if t == nil {
+		return errors.New("cfg is nil")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return errors.New("cfg must be a pointer to struct")
+	}
+
+	if _, err := fmt.Fprintln(w, "| Key | Env | Type | Default | Rules |"); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "| --- | --- | --- | --- | --- |"); err != nil {
+		return err
+	}
+	row := func(key, env string, field reflect.StructField) error {
+		_, err := fmt.Fprintf(w, "| `%s` | %s | `%s` | %s | %s |\n",
+			key, env, field.Type.String(), mdCode(field.Tag.Get("default")), mdCode(field.Tag.Get("validate")))
+		return err
+	}
+	return walkLeaves(t, "", func(key string, field reflect.StructField) error {
+		if err := row(key, mdCode(envName(envPrefix, key)), field); err != nil {
+			return err
+		}
+		if field.Type.Kind() != reflect.Map || structElem(field.Type) == nil {
+			return nil
+		}
+		return walkLeaves(structElem(field.Type), key+".<name>", func(sub string, sf reflect.StructField) error {
+			return row(sub, mdCode(envName(envPrefix, key)+"_<NAME>_"+envName("", strings.TrimPrefix(sub, key+".<name>."))), sf)
+		})
+	})
+}
+
+func mdCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+	"mini-jupiter/pkg/isolation"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	var cfg appConfig
+	m, err := Load("", &cfg, WithSources[appConfig](Map("test", map[string]any{
+		"app.name":                             "test",
+		"ratelimit.enabled":                    true,
+		"isolation.routes./ping.max_queue":     5,
+		"isolation.routes./api.max_concurrent": 3,
+	})))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	testutil.Equal(t, cfg.App.Env, "dev")
+	testutil.Equal(t, cfg.HTTP.Addr, ":8080")
+	testutil.Equal(t, cfg.RateLimit.Rate, 1.0)
+	testutil.Equal(t, cfg.RateLimit.Burst, 1)
+	testutil.Equal(t, cfg.Metric.Namespace, "mini_jupiter")
+	// map 中的每个条目按元素结构体的 default 标签补齐
+	testutil.Equal(t, cfg.Isolation.Routes, map[string]isolation.RouteConfig{
+		"/ping": {MaxConcurrent: 1, MaxQueue: 5, WaitTimeoutMs: 50},
+		"/api":  {MaxConcurrent: 3, WaitTimeoutMs: 50},
+	})
+	testutil.Equal(t, m.Origin("ratelimit.rate"), originDefault)
+	testutil.Equal(t, m.Origin("isolation.routes./ping.wait_timeout_ms"), originDefault)
+	testutil.Equal(t, m.Origin("app.name"), "test")
+}
+
+func TestValidateRejectsOutOfRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value any
+		field string
+	}{
+		{"burst below 1", "ratelimit.burst", 0, "ratelimit.burst"},
+		{"max_concurrent below 1", "isolation.routes./ping.max_concurrent", 0, "isolation.routes./ping.max_concurrent"},
+		{"negative queue", "isolation.routes./ping.max_queue", -1, "isolation.routes./ping.max_queue"},
+		{"unknown encoding", "log.encoding", "xml", "log.encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg appConfig
+			_, err := Load(exampleConfig, &cfg, WithSources[appConfig](Map("test", map[string]any{tt.key: tt.value})))
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Load error = %v, want *ValidationError", err)
+			}
+			if !strings.Contains(err.Error(), tt.field) {
+				t.Errorf("error %q does not mention %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestWriteReference(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReference(&buf, &appConfig{}, "APP"); err != nil {
+		t.Fatalf("WriteReference: %v", err)
+	}
+	for _, row := range []string{
+		"| `ratelimit.burst` | `APP_RATELIMIT_BURST` | `int` | `1` | `min=1` |",
+		"| `isolation.routes.<name>.wait_timeout_ms` | `APP_ISOLATION_ROUTES_<NAME>_WAIT_TIMEOUT_MS` | `int` | `50` | `min=0` |",
+		"| `log.async.flush_interval` | `APP_LOG_ASYNC_FLUSH_INTERVAL` | `time.Duration` | `1s` |  |",
+	} {
+		if !strings.Contains(buf.String(), row) {
+			t.Errorf("reference is missing row %s", row)
+		}
+	}
+}
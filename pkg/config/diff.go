@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type Change struct {
+	Key string
+	Old any
+	New any
+}
+
+// Diff 将新旧配置展开为叶子 key 后逐项比较，返回按 key 排序的变更列表
+func Diff(old, new any) []Change {
+	before := make(map[string]any)
+	after := make(map[string]any)
+	flatten(reflect.ValueOf(old), "", before)
+	flatten(reflect.ValueOf(new), "", after)
+
+	var changes []Change
+	for key, ov := range before {
+		nv, ok := after[key]
+		if !ok || !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, Change{Key: key, Old: ov, New: nv})
+		}
+	}
+	for key, nv := range after {
+		if _, ok := before[key]; !ok {
+			changes = append(changes, Change{Key: key, New: nv})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func flatten(v reflect.Value, prefix string, out map[string]any) {
+	if !v.IsValid() {
+		return
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if prefix != "" {
+				out[prefix] = nil
+			}
+			return
+		}
+		flatten(v.Elem(), prefix, out)
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			key, skip := fieldKey(field)
+			if skip {
+				continue
+			}
+			flatten(v.Field(i), joinKey(prefix, key), out)
+		}
+	case reflect.Map:
+		if v.Len() == 0 && prefix != "" {
+			out[prefix] = v.Interface()
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			flatten(iter.Value(), joinKey(prefix, fmt.Sprint(iter.Key().Interface())), out)
+		}
+	default:
+		if prefix != "" {
+			out[prefix] = v.Interface()
+		}
+	}
+}
+
+// valueAt 按点分 key 取出 cfg 中对应的值，不存在时返回 nil
+func valueAt(cfg any, key string) any {
+	v := reflect.ValueOf(cfg)
+	if key == "" {
+		return v.Interface()
+	}
+	for _, part := range strings.Split(key, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			idx, ok := fieldIndex(v.Type(), part)
+			if !ok {
+				return nil
+			}
+			v = v.Field(idx)
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil
+			}
+			v = v.MapIndex(reflect.ValueOf(part).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return v.Interface()
+}
+
+// typeAt 按点分 key 解析 t 中对应字段的类型，map 的任意 key 均视为合法
+func typeAt(t reflect.Type, key string) (reflect.Type, error) {
+	t, _, err := resolveKey(t, key)
+	return t, err
+}
+
+// resolveKey 在 typeAt 的基础上返回规范形式的 key：字段部分取结构体标签中的名称，
+// map 条目部分转为小写（与 viper 读入的 map key 一致），可直接与 Diff 返回的 key 比较
+func resolveKey(t reflect.Type, key string) (reflect.Type, string, error) {
+	if key == "" {
+		return t, "", nil
+	}
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			idx, ok := fieldIndex(t, part)
+			if !ok {
+				return nil, "", fmt.Errorf("unknown config key %q", key)
+			}
+			parts[i], _ = fieldKey(t.Field(idx))
+			t = t.Field(idx).Type
+		case reflect.Map:
+			parts[i] = strings.ToLower(part)
+			t = t.Elem()
+		default:
+			return nil, "", fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	return t, strings.Join(parts, "."), nil
+}
+
+func fieldIndex(t reflect.Type, key string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name, skip := fieldKey(field)
+		if !skip && strings.EqualFold(name, key) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func keyMatches(sub, changed string) bool {
+	if sub == "" || sub == changed {
+		return true
+	}
+	return strings.HasPrefix(changed, sub+".") || strings.HasPrefix(sub, changed+".")
+}
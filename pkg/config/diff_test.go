@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+	"mini-jupiter/pkg/ratelimiter"
+)
+
+func TestDiff(t *testing.T) {
+	var old, cfg appConfig
+	old.RateLimit.Rate = 1
+	old.Log.Modules = map[string]string{"pool": "info"}
+	cfg = old
+	cfg.RateLimit.Rate = 2
+	cfg.Log.Modules = map[string]string{"pool": "debug", "http": "warn"}
+
+	got := Diff(&old, &cfg)
+	want := []Change{
+		{Key: "log.modules.http", New: "warn"},
+		{Key: "log.modules.pool", Old: "info", New: "debug"},
+		{Key: "ratelimit.rate", Old: 1.0, New: 2.0},
+	}
+	testutil.Equal(t, got, want)
+	testutil.Equal(t, len(Diff(&cfg, &cfg)), 0)
+}
+
+func TestSubscribe(t *testing.T) {
+	values := map[string]any{"ratelimit.rate": 5}
+	var cfg appConfig
+	m, err := Load(exampleConfig, &cfg, WithSources[appConfig](Map("test", values)))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	fired := make(map[string]int)
+	for _, key := range []string{"ratelimit", "RateLimit", "ratelimit.Rate", "ratelimit.burst", "isolation", ""} {
+		key := key
+		if _, err := m.Subscribe(key, func(_, _ any) { fired[key]++ }); err != nil {
+			t.Fatalf("Subscribe(%q): %v", key, err)
+		}
+	}
+	var got ratelimiter.Config
+	unsubscribe, err := SubscribeValue(m, "RATELIMIT", func(_, c ratelimiter.Config) { got = c })
+	if err != nil {
+		t.Fatalf("SubscribeValue: %v", err)
+	}
+
+	values["ratelimit.rate"] = 8
+	m.reload("test")
+	testutil.Equal(t, fired, map[string]int{"ratelimit": 1, "RateLimit": 1, "ratelimit.Rate": 1, "": 1})
+	testutil.Equal(t, got.Rate, 8.0)
+
+	unsubscribe()
+	values["ratelimit.rate"] = 9
+	m.reload("test")
+	testutil.Equal(t, got.Rate, 8.0)
+
+	if _, err := m.Subscribe("ratelimit.unknown", func(_, _ any) {}); err == nil {
+		t.Error("Subscribe accepted an unknown key")
+	}
+	if _, err := SubscribeValue(m, "ratelimit.rate", func(_, _ int) {}); err == nil {
+		t.Error("SubscribeValue accepted a mismatched type")
+	}
+}
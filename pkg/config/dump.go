@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// originDefault 表示值来自 default 标签，originUnset 表示没有任何来源设置该 key（取零值）
+	originDefault = "default"
+	originUnset   = "unset"
+)
+
+type dumpEntry struct {
+	Value  any    `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// Dump 输出当前生效配置，密钥类的值已掩码：
+// yaml 以行尾注释标注每个值的来源，json 的叶子节点为 {"value": ..., "origin": ...}
+func (m *Manager[T]) Dump(format string) ([]byte, error) {
+	leaves := m.Redacted()
+	origins := m.Origins()
+	originOf := func(key string) string {
+		if o, ok := origins[strings.ToLower(key)]; ok {
+			return o
+		}
+		return originUnset
+	}
+
+	switch strings.ToLower(format) {
+	case "", "yaml", "yml":
+		root := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range sortedKeys(leaves) {
+			if err := setYAMLPath(root, strings.Split(key, "."), leaves[key], originOf(key)); err != nil {
+				return nil, fmt.Errorf("dump %s: %w", key, err)
+			}
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(root); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "json":
+		tree := make(map[string]any)
+		for key, val := range leaves {
+			setLeaf(tree, strings.Split(key, "."), dumpEntry{Value: val, Origin: originOf(key)})
+		}
+		return json.MarshalIndent(tree, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported dump format %q", format)
+	}
+}
+
+// Handler 提供只读的配置查看接口，?format=json|yaml（默认 yaml）
+func (m *Manager[T]) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		out, err := m.Dump(format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if strings.ToLower(format) == "json" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "application/yaml")
+		}
+		w.Header().Set("X-Config-Hash", m.Hash())
+		_, _ = w.Write(out)
+	})
+}
+
+func setLeaf(tree map[string]any, parts []string, val any) {
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := tree[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			tree[p] = next
+		}
+		tree = next
+	}
+	tree[parts[len(parts)-1]] = val
+}
+
+func setYAMLPath(node *yaml.Node, parts []string, val any, origin string) error {
+	for _, p := range parts[:len(parts)-1] {
+		node = yamlChild(node, p)
+	}
+	valNode := &yaml.Node{}
+	if err := valNode.Encode(val); err != nil {
+		return err
+	}
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: parts[len(parts)-1]}
+	// 非空的切片/map 跨多行输出，行尾注释需挂在 key 上，否则会被渲染到下一个 key 之后；
+	// 标量与空集合（[]、{}）与 key 同行，注释挂在值上
+	if valNode.Kind == yaml.ScalarNode || len(valNode.Content) == 0 {
+		valNode.LineComment = origin
+	} else {
+		keyNode.LineComment = origin
+	}
+	node.Content = append(node.Content, keyNode, valNode)
+	return nil
+}
+
+func yamlChild(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+	return child
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
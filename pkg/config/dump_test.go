@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+func TestDumpYAMLOrigins(t *testing.T) {
+	_, m := loadExample(t, map[string]string{
+		"LOG_OUTPUT_PATHS": "stdout,/tmp/app.log",
+		"HTTP_ADDR":        ":9090",
+	})
+	out, err := m.Dump("yaml")
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	lines := strings.Split(string(out), "\n")
+	lineOf := func(prefix string) string {
+		t.Helper()
+		for _, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), prefix) {
+				return strings.TrimSpace(l)
+			}
+		}
+		t.Fatalf("no line %q in dump:\n%s", prefix, out)
+		return ""
+	}
+
+	file := "# file:" + exampleConfig
+	tests := []struct {
+		prefix, want string
+	}{
+		{"addr: :", `addr: :9090 # env:HTTP_ADDR`},
+		{"name:", `name: mini-jupiter ` + file},
+		// 切片/map 的来源注释在 key 所在行
+		{"output_paths:", `output_paths: # env:LOG_OUTPUT_PATHS`},
+		{"keys:", `keys: ` + file},
+		// default 标签与未设置（零值）区分开
+		{"error_output_paths:", `error_output_paths: [] # unset`},
+		{"path:", `path: /metrics ` + file},
+		{"flush_interval:", `flush_interval: 1s ` + file},
+		{"disabled:", `disabled: false # unset`},
+		{"mask:", `mask: '******' # default`},
+	}
+	for _, tt := range tests {
+		if got := lineOf(tt.prefix); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+	// 切片元素紧跟在 key 之后，下一个 key 不带多余注释
+	if i := strings.Index(string(out), "output_paths: # env:LOG_OUTPUT_PATHS\n"); i < 0 ||
+		!strings.HasPrefix(string(out)[i:], "output_paths: # env:LOG_OUTPUT_PATHS\n    - stdout\n    - /tmp/app.log\n") {
+		t.Errorf("unexpected output_paths rendering:\n%s", out)
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	_, m := loadExample(t, map[string]string{"RATELIMIT_BURST": "3"})
+	out, err := m.Dump("json")
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	var tree map[string]map[string]dumpEntry
+	if err := json.Unmarshal(out, &tree); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, out)
+	}
+	testutil.Equal(t, tree["ratelimit"]["burst"], dumpEntry{Value: 3.0, Origin: "env:RATELIMIT_BURST"})
+	testutil.Equal(t, tree["app"]["env"].Origin, "file:"+exampleConfig)
+
+	if _, err := m.Dump("toml"); err == nil {
+		t.Error("Dump(toml) succeeded")
+	}
+}
+
+func TestDumpHandler(t *testing.T) {
+	_, m := loadExample(t, nil)
+	h := m.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/config?format=json", nil))
+	testutil.Equal(t, rec.Code, http.StatusOK)
+	testutil.Equal(t, rec.Header().Get("Content-Type"), "application/json")
+	testutil.Equal(t, rec.Header().Get("X-Config-Hash"), m.Hash())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/config", nil))
+	testutil.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	applog "mini-jupiter/pkg/log"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Provider 是可监听变更的配置来源：Load 读取当前内容，Watch 阻塞直到 ctx 结束，
+// 期间每次检测到变更调用 notify（参数为变更描述，用于日志）
+type Provider interface {
+	Source
+	Watch(ctx context.Context, notify func(event string)) error
+}
+
+func (s *fileSource) Watch(ctx context.Context, notify func(event string)) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	// 监听所在目录而不是文件本身，兼容编辑器“写临时文件再 rename”以及 k8s ConfigMap 的软链替换
+	file := filepath.Clean(s.path)
+	dir := filepath.Dir(file)
+	if err := w.Add(dir); err != nil {
+		return err
+	}
+	realFile, _ := filepath.EvalSymlinks(file)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			// fsnotify 的错误（如事件队列溢出）不影响后续事件，记录后继续监听
+			applog.Named("config").Warn("config watch error",
+				zap.String("source", s.Name()),
+				zap.Error(err),
+			)
+		case e, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			current, _ := filepath.EvalSymlinks(file)
+			if (filepath.Clean(e.Name) == file && e.Has(fsnotify.Write|fsnotify.Create)) ||
+				(current != "" && current != realFile) {
+				realFile = current
+				notify(file)
+			}
+		}
+	}
+}
+
+type HTTPOption func(*httpProvider)
+
+// WithHTTPClient 指定请求使用的 client，默认超时 5s
+func WithHTTPClient(c *http.Client) HTTPOption {
+	return func(p *httpProvider) {
+		if c != nil {
+			p.client = c
+		}
+	}
+}
+
+func WithHTTPHeader(key, value string) HTTPOption {
+	return func(p *httpProvider) {
+		p.header.Set(key, value)
+	}
+}
+
+func WithPollInterval(d time.Duration) HTTPOption {
+	return func(p *httpProvider) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithFormat 指定响应格式（yaml/json），默认按 Content-Type 或 URL 后缀推断
+func WithFormat(format string) HTTPOption {
+	return func(p *httpProvider) {
+		p.format = format
+	}
+}
+
+type httpProvider struct {
+	url      string
+	client   *http.Client
+	header   http.Header
+	interval time.Duration
+	format   string
+
+	mu     sync.Mutex
+	etag   string
+	digest [sha256.Size]byte
+	values map[string]any
+}
+
+// HTTP 轮询一个返回 YAML/JSON 的地址，支持 ETag / If-None-Match，未变更时复用上次结果
+func HTTP(url string, opts ...HTTPOption) Provider {
+	p := &httpProvider{
+		url:      url,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		header:   make(http.Header),
+		interval: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *httpProvider) Name() string {
+	return "http:" + p.url
+}
+
+// Load 返回 Watch 最近一次拉取的结果，尚无缓存（首次加载）时才请求远端
+func (p *httpProvider) Load() (map[string]any, error) {
+	p.mu.Lock()
+	values := p.values
+	p.mu.Unlock()
+	if values != nil {
+		return values, nil
+	}
+	if _, err := p.fetch(context.Background()); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.values, nil
+}
+
+func (p *httpProvider) Watch(ctx context.Context, notify func(event string)) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := p.fetch(ctx)
+			if err != nil {
+				// 拉取失败时保留上次结果，下个周期重试
+				if ctx.Err() == nil {
+					applog.Named("config").Warn("config poll failed",
+						zap.String("source", p.Name()),
+						zap.Error(err),
+					)
+				}
+				continue
+			}
+			if changed {
+				notify(p.Name())
+			}
+		}
+	}
+}
+
+// fetch 拉取远端配置并更新缓存，返回内容是否发生变化
+func (p *httpProvider) fetch(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	for k, vs := range p.header {
+		req.Header[k] = vs
+	}
+	p.mu.Lock()
+	etag := p.etag
+	cached := p.values != nil
+	p.mu.Unlock()
+	if etag != "" && cached {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	digest := sha256.Sum256(body)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if cached && digest == p.digest {
+		p.etag = resp.Header.Get("ETag")
+		return false, nil
+	}
+	values, err := parseConfig(body, p.formatOf(resp))
+	if err != nil {
+		return false, err
+	}
+	p.etag = resp.Header.Get("ETag")
+	p.digest = digest
+	p.values = values
+	return cached, nil
+}
+
+func (p *httpProvider) formatOf(resp *http.Response) string {
+	if p.format != "" {
+		return p.format
+	}
+	if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		switch {
+		case strings.HasSuffix(mt, "json"):
+			return "json"
+		case strings.HasSuffix(mt, "yaml"), strings.HasSuffix(mt, "yml"):
+			return "yaml"
+		}
+	}
+	if ext := strings.TrimPrefix(path.Ext(resp.Request.URL.Path), "."); ext != "" {
+		return ext
+	}
+	return "yaml"
+}
+
+func parseConfig(body []byte, format string) (map[string]any, error) {
+	if format == "" {
+		return nil, errors.New("config format is empty")
+	}
+	v := viper.New()
+	v.SetConfigType(format)
+	if err := v.ReadConfig(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", format, err)
+	}
+	return v.AllSettings(), nil
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mini-jupiter/internal/testutil"
+)
+
+// remoteConfig 是可修改内容的 httptest 配置服务，按内容版本返回 ETag
+type remoteConfig struct {
+	mu      sync.Mutex
+	body    string
+	version int
+	fail    bool
+	hits    atomic.Int32
+}
+
+func (rc *remoteConfig) set(body string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.body = body
+	rc.version++
+}
+
+func (rc *remoteConfig) setFail(fail bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.fail = fail
+}
+
+func (rc *remoteConfig) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.hits.Add(1)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	etag := strconv.Quote(strconv.Itoa(rc.version))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write([]byte(rc.body))
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHTTPProviderReload(t *testing.T) {
+	remote := &remoteConfig{}
+	remote.set("ratelimit:\n  rate: 7\n")
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+
+	var (
+		cfg      appConfig
+		reloads  atomic.Int32
+		failures atomic.Int32
+	)
+	m, err := Load(exampleConfig, &cfg,
+		WithSources[appConfig](HTTP(srv.URL, WithPollInterval(20*time.Millisecond))),
+		WithWatch[appConfig](),
+		WithOnChange(func(_, _ *appConfig) { reloads.Add(1) }),
+		WithOnReloadError[appConfig](func(error) { failures.Add(1) }),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer m.Close()
+	testutil.Equal(t, cfg.RateLimit.Rate, 7.0)
+	testutil.Equal(t, m.Origin("ratelimit.rate"), "http:"+srv.URL)
+	testutil.Equal(t, remote.hits.Load(), int32(1))
+
+	remote.set("ratelimit:\n  rate: 9\n")
+	waitFor(t, "reload", func() bool { return reloads.Load() == 1 })
+	testutil.Equal(t, m.Current().RateLimit.Rate, 9.0)
+	testutil.Equal(t, int(m.Current().RateLimit.Burst), 10)
+
+	// 拉取失败：保留当前配置，不触发重载
+	remote.setFail(true)
+	failedAt := remote.hits.Load()
+	waitFor(t, "failed polls", func() bool { return remote.hits.Load() >= failedAt+3 })
+	remote.setFail(false)
+	testutil.Equal(t, reloads.Load(), int32(1))
+	testutil.Equal(t, failures.Load(), int32(0))
+	testutil.Equal(t, m.Current().RateLimit.Rate, 9.0)
+}
+
+func TestHTTPProviderLoad(t *testing.T) {
+	remote := &remoteConfig{}
+	remote.set(`{"app": {"name": "remote"}}`)
+	srv := httptest.NewServer(remote)
+	defer srv.Close()
+
+	p := HTTP(srv.URL+"/config.json", WithFormat("json"))
+	// 重载时 Load 复用缓存，只有首次加载请求远端
+	for i := 0; i < 3; i++ {
+		values, err := p.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		testutil.Equal(t, values["app"], any(map[string]any{"name": "remote"}))
+	}
+	testutil.Equal(t, remote.hits.Load(), int32(1))
+
+	remote.setFail(true)
+	if _, err := HTTP(srv.URL).Load(); err == nil {
+		t.Error("Load succeeded against a failing endpoint")
+	}
+}
+
+func TestFileSourceWatch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	write := func(rate int) {
+		t.Helper()
+		body := "app:\n  name: test\nratelimit:\n  rate: " + strconv.Itoa(rate) + "\n"
+		// 写临时文件再 rename，与编辑器保存方式一致
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(1)
+
+	var cfg appConfig
+	m, err := Load(path, &cfg, WithWatch[appConfig]())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	defer m.Close()
+	// 监听在后台启动，重复写入直到第一次重载生效
+	waitFor(t, "reload", func() bool {
+		write(2)
+		time.Sleep(20 * time.Millisecond)
+		return m.Current().RateLimit.Rate == 2
+	})
+	write(3)
+	waitFor(t, "reload", func() bool { return m.Current().RateLimit.Rate == 3 })
+}
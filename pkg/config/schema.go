@@ -0,0 +1,529 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	sizeType     = reflect.TypeOf(Size(0))
+	secretType   = reflect.TypeOf(Secret(""))
+)
+
+// Schema 是 JSON Schema（2020-12）的子集，足以描述配置结构体
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+}
+
+// JSONSchema 根据配置结构体（指针）生成 JSON Schema，类型、嵌套结构、
+// default 标签与 validate 标签中的 required/min/max/oneof 都会体现在 schema 中
+func JSONSchema(cfg any) (*Schema, error) {
+	t := reflect.TypeOf(cfg)
+	if t == nil {
+		return nil, errors.New("cfg is nil")
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("cfg must be a pointer to struct")
+	}
+	s := schemaOf(t)
+	s.Schema = schemaDraft
+	s.Title = t.Name()
+	return s, nil
+}
+
+// ValidateFile 在不启动服务的情况下校验配置文件：先按 JSON Schema 检查结构与取值，
+// 再补齐默认值、解码为 T 并执行 validate 标签与 Validate() 方法（不读取环境变量）；
+// key 与加载时一样不区分大小写，类型按加载时的弱类型规则检查（如 "5"、逗号分隔的切片），
+// 包含 ${scheme:ref} 引用的值不做类型与取值检查
+func ValidateFile[T any](path string) error {
+	schema, err := JSONSchema(new(T))
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	var errs []FieldError
+	// 与 viper 一致，key 不区分大小写
+	schema.validate(lowerKeys(doc), "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	settings, err := File(path).Load()
+	if err != nil {
+		return err
+	}
+	// ${scheme:ref} 引用的值只有在运行时才能解析，先移除，其余字段照常补齐默认值并校验
+	refs := make(map[string]bool)
+	stripRefs(settings, "", refs)
+	applyDefaults(settings, make(map[string]string), reflect.TypeOf(new(T)).Elem(), "")
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	cfg := new(T)
+	if err := v.Unmarshal(cfg, viper.DecodeHook(decodeHook())); err != nil {
+		return fmt.Errorf("unmarshal config: %w", err)
+	}
+	err = validateStruct(cfg)
+	var verr *ValidationError
+	if len(refs) == 0 || !errors.As(err, &verr) {
+		return err
+	}
+	kept := verr.Errors[:0]
+	for _, fe := range verr.Errors {
+		if !refs[strings.ToLower(fe.Field)] {
+			kept = append(kept, fe)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: kept}
+}
+
+// hasRef 判断值是否包含 ${scheme:ref} 引用
+func hasRef(val any) bool {
+	switch v := val.(type) {
+	case string:
+		return secretRef.MatchString(v)
+	case []any:
+		for _, item := range v {
+			if hasRef(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stripRefs 删除 settings 中包含引用的叶子，并记录其 key
+func stripRefs(settings map[string]any, prefix string, refs map[string]bool) {
+	for k, v := range settings {
+		key := joinKey(prefix, k)
+		if m, ok := v.(map[string]any); ok {
+			stripRefs(m, key, refs)
+			continue
+		}
+		if hasRef(v) {
+			delete(settings, k)
+			refs[key] = true
+		}
+	}
+}
+
+func lowerKeys(val any) any {
+	switch v := val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[strings.ToLower(k)] = lowerKeys(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = lowerKeys(item)
+		}
+		return out
+	}
+	return val
+}
+
+func schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return &Schema{Type: []string{"string", "integer"}, Format: "duration"}
+	case sizeType:
+		return &Schema{Type: []string{"string", "integer"}, Format: "size"}
+	case secretType:
+		return &Schema{Type: "string", WriteOnly: true}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			key, skip := fieldKey(field)
+			if skip {
+				continue
+			}
+			fs := schemaOf(field.Type)
+			if def, ok := field.Tag.Lookup("default"); ok {
+				fs.Default = typedDefault(field.Type, def)
+			}
+			if applyRules(fs, field.Tag.Get("validate")) {
+				s.Required = append(s.Required, key)
+			}
+			s.Properties[key] = fs
+		}
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// applyRules 将 validate 标签映射为 schema 约束，返回是否 required
+func applyRules(s *Schema, tag string) bool {
+	required, omitempty := false, false
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "omitempty":
+			omitempty = true
+		case "required":
+			required = true
+			if s.Type == "string" {
+				one := 1
+				s.MinLength = &one
+			}
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, name == "min", n)
+		case "oneof":
+			for _, v := range strings.Fields(arg) {
+				if s.Type == "integer" {
+					if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		}
+	}
+	if omitempty && len(s.Enum) > 0 && s.Type == "string" {
+		s.Enum = append(s.Enum, "")
+	}
+	return required
+}
+
+func setBound(s *Schema, min bool, n float64) {
+	switch s.Type {
+	case "integer", "number":
+		if min {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	case "string":
+		l := int(n)
+		if min {
+			s.MinLength = &l
+		} else {
+			s.MaxLength = &l
+		}
+	case "array":
+		l := int(n)
+		if min {
+			s.MinItems = &l
+		} else {
+			s.MaxItems = &l
+		}
+	}
+}
+
+func typedDefault(t reflect.Type, def string) any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType || t == sizeType {
+		return def
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(def); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(def, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(def, 64); err == nil {
+			return n
+		}
+	case reflect.Slice:
+		var out []any
+		for _, p := range strings.Split(def, ",") {
+			out = append(out, strings.TrimSpace(p))
+		}
+		return out
+	}
+	return def
+}
+
+func (s *Schema) validate(val any, path string, errs *[]FieldError) {
+	field := path
+	if field == "" {
+		field = "<root>"
+	}
+	fail := func(rule, msg string) {
+		*errs = append(*errs, FieldError{Field: field, Rule: rule, Err: msg})
+	}
+	if hasRef(val) {
+		// 引用在加载时才解析为实际值，此处无法检查类型与取值
+		return
+	}
+	val = s.weaken(val)
+	if !s.matchesType(val) {
+		fail("type", fmt.Sprintf("must be %v", s.Type))
+		return
+	}
+	switch s.Format {
+	case "duration":
+		if str, ok := val.(string); ok {
+			if _, err := time.ParseDuration(str); err != nil {
+				fail("format", "must be a duration like 200ms")
+			}
+		}
+	case "size":
+		if str, ok := val.(string); ok {
+			if _, err := ParseSize(str); err != nil {
+				fail("format", "must be a size like 100MB")
+			}
+		}
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, val) {
+		fail("enum", fmt.Sprintf("must be one of %v", s.Enum))
+	}
+	if n, ok := toFloat(val); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("minimum", fmt.Sprintf("must be >= %v", *s.Minimum))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("maximum", fmt.Sprintf("must be <= %v", *s.Maximum))
+		}
+	}
+	switch v := val.(type) {
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			fail("minLength", fmt.Sprintf("length must be >= %d", *s.MinLength))
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			fail("maxLength", fmt.Sprintf("length must be <= %d", *s.MaxLength))
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("minItems", fmt.Sprintf("must have >= %d items", *s.MinItems))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("maxItems", fmt.Sprintf("must have <= %d items", *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case map[string]any:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok && s.Properties[key].Default == nil {
+				*errs = append(*errs, FieldError{Field: joinKey(path, key), Rule: "required", Err: "is required"})
+			}
+		}
+		for _, key := range sortedKeys(v) {
+			if prop, ok := s.Properties[key]; ok {
+				prop.validate(v[key], joinKey(path, key), errs)
+				continue
+			}
+			switch ap := s.AdditionalProperties.(type) {
+			case *Schema:
+				ap.validate(v[key], joinKey(path, key), errs)
+			case bool:
+				if !ap {
+					*errs = append(*errs, FieldError{Field: joinKey(path, key), Rule: "additionalProperties", Err: "unknown key"})
+				}
+			}
+		}
+	}
+}
+
+func (s *Schema) matchesType(val any) bool {
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	default:
+		return true
+	}
+	for _, t := range types {
+		switch t {
+		case "string":
+			if _, ok := val.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := val.(bool); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := toFloat(val); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat(val); ok {
+				return true
+			}
+		case "array":
+			if _, ok := val.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := val.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// weaken 按 Load 的弱类型解码规则（viper 的 WeaklyTypedInput 与 decodeHook）转换类型不符的值，
+// 使离线校验与加载接受同样的写法：数字、布尔的字符串形式，逗号分隔的切片，标量转单元素切片等
+func (s *Schema) weaken(val any) any {
+	t, ok := s.Type.(string)
+	if !ok || s.matchesType(val) {
+		return val
+	}
+	switch t {
+	case "integer", "number":
+		switch v := val.(type) {
+		case string:
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n
+			}
+		case bool:
+			if v {
+				return 1
+			}
+			return 0
+		}
+	case "boolean":
+		switch v := val.(type) {
+		case string:
+			if v == "" {
+				return false
+			}
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		default:
+			if n, ok := toFloat(v); ok {
+				return n != 0
+			}
+		}
+	case "string":
+		switch v := val.(type) {
+		case bool, int, int64, uint64:
+			return fmt.Sprint(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	case "array":
+		switch v := val.(type) {
+		case string:
+			out := []any{}
+			if v != "" {
+				for _, part := range strings.Split(v, ",") {
+					out = append(out, part)
+				}
+			}
+			return out
+		case map[string]any:
+		default:
+			return []any{v}
+		}
+	case "object":
+		if a, ok := val.([]any); ok && len(a) == 0 {
+			return map[string]any{}
+		}
+	}
+	return val
+}
+
+func toFloat(val any) (float64, bool) {
+	switch n := val.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func inEnum(enum []any, val any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(val) {
+			return true
+		}
+	}
+	return false
+}
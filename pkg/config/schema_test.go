@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+func TestJSONSchema(t *testing.T) {
+	s, err := JSONSchema(&appConfig{})
+	if err != nil {
+		t.Fatalf("JSONSchema: %v", err)
+	}
+	testutil.Equal(t, s.Schema, schemaDraft)
+	testutil.Equal(t, s.AdditionalProperties, any(false))
+
+	app := s.Properties["app"]
+	testutil.Equal(t, app.Required, []string{"name"})
+	burst := s.Properties["ratelimit"].Properties["burst"]
+	testutil.Equal(t, burst.Type, any("integer"))
+	testutil.Equal(t, burst.Default, any(int64(1)))
+	testutil.Equal(t, *burst.Minimum, 1.0)
+
+	route := s.Properties["isolation"].Properties["routes"].AdditionalProperties.(*Schema)
+	testutil.Equal(t, route.Properties["wait_timeout_ms"].Default, any(int64(50)))
+	async := s.Properties["log"].Properties["async"]
+	testutil.Equal(t, async.Properties["flush_interval"].Format, "duration")
+	testutil.Equal(t, async.Properties["overflow"].Enum, []any{"block", "drop_newest", "drop_oldest", ""})
+}
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateFile(t *testing.T) {
+	if err := ValidateFile[appConfig](exampleConfig); err != nil {
+		t.Fatalf("example config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		fields []string
+	}{
+		{
+			name: "keys are case-insensitive like the loader",
+			body: "App:\n  Name: demo\nRateLimit:\n  Enabled: true\n  Rate: 2\nIsolation:\n  Routes:\n    /Ping:\n      Max_Concurrent: 2\n",
+		},
+		{
+			name: "references skip type checks",
+			body: "app:\n  name: ${env:APP_NAME}\nratelimit:\n  enabled: ${env:RL_ON}\n  rate: ${env:RL_RATE}\n  burst: \"${file:/run/secrets/burst}\"\nlog:\n  async:\n    flush_interval: ${env:FLUSH}\n",
+		},
+		{
+			name: "weakly typed values accepted by the loader",
+			body: "app:\n  name: 42\nratelimit:\n  enabled: 'true'\n  rate: '2.5'\n  burst: '5'\nlog:\n  output_paths: stdout,/tmp/app.log\n  error_output_paths: stderr\nmiddleware:\n  logging: 1\n",
+		},
+		{
+			name:   "weak conversion still checks the value",
+			body:   "app:\n  name: demo\nratelimit:\n  burst: '0'\n  rate: 'x'\nmiddleware:\n  logging: maybe\n",
+			fields: []string{"middleware.logging", "ratelimit.burst", "ratelimit.rate"},
+		},
+		{
+			name:   "unknown key",
+			body:   "app:\n  name: demo\n  nmae: typo\n",
+			fields: []string{"app.nmae"},
+		},
+		{
+			name:   "wrong type and range",
+			body:   "app:\n  name: demo\nratelimit:\n  rate: fast\n  burst: 0\nlog:\n  async:\n    flush_interval: soon\n",
+			fields: []string{"log.async.flush_interval", "ratelimit.burst", "ratelimit.rate"},
+		},
+		{
+			name:   "required",
+			body:   "http:\n  addr: ':80'\n",
+			fields: []string{"app.name"},
+		},
+		{
+			name:   "Validate method still runs",
+			body:   "app:\n  name: demo\nratelimit:\n  enabled: true\n  rate: 0\n",
+			fields: []string{"ratelimit"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFile[appConfig](writeConfig(t, tt.body))
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("ValidateFile: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("ValidateFile error = %v, want *ValidationError", err)
+			}
+			var fields []string
+			for _, fe := range verr.Errors {
+				fields = append(fields, fe.Field)
+			}
+			testutil.Equal(t, fields, tt.fields)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const redacted = "******"
+
+// SecretResolver 解析 ${scheme:ref} 形式的引用，返回明文
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+type SecretResolverFunc func(ref string) (string, error)
+
+func (f SecretResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+// Secret 用于声明敏感字段，打印、JSON/YAML 序列化时均输出掩码，取明文需调用 Value
+type Secret string
+
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("config.Secret(%q)", s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) MarshalYAML() (any, error) {
+	return s.String(), nil
+}
+
+var secretRef = regexp.MustCompile(`\$\{([a-zA-Z][a-zA-Z0-9_-]*):([^}]*)\}`)
+
+func defaultResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{
+		"env": SecretResolverFunc(func(ref string) (string, error) {
+			val, ok := os.LookupEnv(ref)
+			if !ok {
+				return "", fmt.Errorf("env %s is not set", ref)
+			}
+			return val, nil
+		}),
+		"file": SecretResolverFunc(func(ref string) (string, error) {
+			b, err := os.ReadFile(ref)
+			if err != nil {
+				return "", err
+			}
+			return strings.TrimRight(string(b), "\r\n"), nil
+		}),
+	}
+}
+
+// resolveSecrets 原地替换 settings 中的引用，返回包含引用的叶子 key
+func resolveSecrets(settings map[string]any, resolvers map[string]SecretResolver) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	if err := resolveMap(settings, "", resolvers, keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func resolveMap(m map[string]any, prefix string, resolvers map[string]SecretResolver, keys map[string]bool) error {
+	for k, v := range m {
+		key := joinKey(prefix, k)
+		switch val := v.(type) {
+		case map[string]any:
+			if err := resolveMap(val, key, resolvers, keys); err != nil {
+				return err
+			}
+		case string:
+			out, found, err := resolveString(val, resolvers)
+			if err != nil {
+				return fmt.Errorf("resolve %s: %w", key, err)
+			}
+			if found {
+				m[k] = out
+				keys[key] = true
+			}
+		case []any:
+			// 切片与来源（Map、HTTP provider 的缓存）共享，替换前先复制，保证下次加载仍能看到引用
+			var copied []any
+			for i, item := range val {
+				s, ok := item.(string)
+				if !ok {
+					continue
+				}
+				out, found, err := resolveString(s, resolvers)
+				if err != nil {
+					return fmt.Errorf("resolve %s[%d]: %w", key, i, err)
+				}
+				if found {
+					if copied == nil {
+						copied = append([]any(nil), val...)
+						m[k] = copied
+					}
+					copied[i] = out
+					keys[key] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func resolveString(s string, resolvers map[string]SecretResolver) (string, bool, error) {
+	if !strings.Contains(s, "${") {
+		return s, false, nil
+	}
+	var firstErr error
+	found := false
+	out := secretRef.ReplaceAllStringFunc(s, func(ref string) string {
+		parts := secretRef.FindStringSubmatch(ref)
+		found = true
+		r, ok := resolvers[parts[1]]
+		if !ok {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("unknown secret scheme %q", parts[1])
+			}
+			return ""
+		}
+		val, err := r.Resolve(parts[2])
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", parts[1], err)
+		}
+		return val
+	})
+	if firstErr != nil {
+		return "", false, firstErr
+	}
+	return out, found, nil
+}
+
+// redactedTree 展开配置为叶子 key，Secret 字段及由引用解析得到的值以掩码替代
+func redactedTree(cfg any, secrets map[string]bool) map[string]any {
+	leaves := make(map[string]any)
+	flatten(reflect.ValueOf(cfg), "", leaves)
+	for key, val := range leaves {
+		if secrets[key] || isSecretValue(val) {
+			leaves[key] = redacted
+		}
+	}
+	return leaves
+}
+
+func isSecretValue(val any) bool {
+	switch v := val.(type) {
+	case Secret:
+		return v != ""
+	case []Secret:
+		return len(v) > 0
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+type secretConfig struct {
+	DB struct {
+		User     string   `mapstructure:"user" yaml:"user"`
+		Password string   `mapstructure:"password" yaml:"password"`
+		DSN      string   `mapstructure:"dsn" yaml:"dsn"`
+		Token    Secret   `mapstructure:"token" yaml:"token"`
+		Hosts    []string `mapstructure:"hosts" yaml:"hosts"`
+	} `mapstructure:"db" yaml:"db"`
+}
+
+func loadSecrets(t *testing.T, values map[string]any, opts ...Option[secretConfig]) (*secretConfig, *Manager[secretConfig], error) {
+	t.Helper()
+	var cfg secretConfig
+	opts = append(opts, WithSources[secretConfig](Map("test", values)))
+	m, err := Load("", &cfg, opts...)
+	return &cfg, m, err
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("DB_PASS", "s3cret")
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("tok-123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vault := SecretResolverFunc(func(ref string) (string, error) { return "vault-" + ref, nil })
+
+	cfg, m, err := loadSecrets(t, map[string]any{
+		"db.user":     "app",
+		"db.password": "${env:DB_PASS}",
+		"db.dsn":      "app:${vault:db}@tcp(db:3306)/app",
+		"db.token":    "${file:" + file + "}",
+	}, WithSecretResolver[secretConfig]("vault", vault))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	testutil.Equal(t, cfg.DB.Password, "s3cret")
+	testutil.Equal(t, cfg.DB.DSN, "app:vault-db@tcp(db:3306)/app")
+	testutil.Equal(t, cfg.DB.Token.Value(), "tok-123")
+	testutil.Equal(t, fmt.Sprint(cfg.DB.Token), redacted)
+
+	red := m.Redacted()
+	testutil.Equal(t, red["db.user"], any("app"))
+	for _, key := range []string{"db.password", "db.dsn", "db.token"} {
+		testutil.Equal(t, red[key], any(redacted))
+	}
+	for _, format := range []string{"yaml", "json"} {
+		out, err := m.Dump(format)
+		if err != nil {
+			t.Fatalf("Dump(%s): %v", format, err)
+		}
+		for _, secret := range []string{"s3cret", "vault-db", "tok-123"} {
+			if strings.Contains(string(out), secret) {
+				t.Errorf("Dump(%s) leaks %q:\n%s", format, secret, out)
+			}
+		}
+	}
+}
+
+func TestResolveSecretsKeepsSourceReferences(t *testing.T) {
+	src := Map("mem", map[string]any{"db.hosts": []any{"${env:DB_HOST}", "b"}})
+	for _, host := range []string{"host-1", "host-2"} {
+		t.Setenv("DB_HOST", host)
+		var cfg secretConfig
+		m, err := Load("", &cfg, WithSources[secretConfig](src))
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		// 每次加载都重新解析引用（密钥轮换），且仍按密钥掩码
+		testutil.Equal(t, cfg.DB.Hosts, []string{host, "b"})
+		testutil.Equal(t, m.Redacted()["db.hosts"], any(redacted))
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	for _, ref := range []string{"${env:MINI_JUPITER_UNSET}", "${vault:db}", "${file:/nonexistent/secret}"} {
+		if _, _, err := loadSecrets(t, map[string]any{"db.password": ref}); err == nil {
+			t.Errorf("Load with %s succeeded, want error", ref)
+		}
+	}
+}
+
+func TestHashExcludesSecrets(t *testing.T) {
+	hashOf := func(password, user string) string {
+		t.Helper()
+		t.Setenv("DB_PASS", password)
+		_, m, err := loadSecrets(t, map[string]any{"db.user": user, "db.password": "${env:DB_PASS}", "db.token": password})
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return m.Hash()
+	}
+	base := hashOf("a", "app")
+	if base == "" {
+		t.Fatal("empty hash")
+	}
+	testutil.Equal(t, hashOf("a", "app"), base)
+	testutil.Equal(t, hashOf("b", "app"), base)
+	if hashOf("a", "other") == base {
+		t.Error("hash did not change with a non-secret value")
+	}
+}
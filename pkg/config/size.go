@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Size 表示字节数，配置中可写作整数或带单位的字符串（如 "512KB"、"100MB"、"1GiB"），
+// 单位按 1024 进制计算，KB 与 KiB 等价
+type Size int64
+
+const (
+	Byte Size = 1
+	KB        = 1024 * Byte
+	MB        = 1024 * KB
+	GB        = 1024 * MB
+	TB        = 1024 * GB
+)
+
+var sizeUnits = []struct {
+	suffix string
+	size   Size
+}{
+	{"tib", TB}, {"gib", GB}, {"mib", MB}, {"kib", KB},
+	{"tb", TB}, {"gb", GB}, {"mb", MB}, {"kb", KB},
+	{"t", TB}, {"g", GB}, {"m", MB}, {"k", KB},
+	{"b", Byte},
+}
+
+func ParseSize(s string) (Size, error) {
+	raw := strings.ToLower(strings.TrimSpace(s))
+	if raw == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit := Byte
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(raw, u.suffix) {
+			raw = strings.TrimSpace(strings.TrimSuffix(raw, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return Size(n * float64(unit)), nil
+}
+
+func (s Size) Bytes() int64 {
+	return int64(s)
+}
+
+func (s Size) String() string {
+	for _, u := range []struct {
+		suffix string
+		size   Size
+	}{{"GB", GB}, {"MB", MB}, {"KB", KB}} {
+		if s >= u.size && s%u.size == 0 {
+			return strconv.FormatInt(int64(s/u.size), 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(int64(s), 10) + "B"
+}
+
+func (s Size) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Size) UnmarshalText(text []byte) error {
+	v, err := ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
@@ -0,0 +1,459 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Source 是一层配置来源，Load 返回嵌套的 key/value（key 不区分大小写）
+type Source interface {
+	Name() string
+	Load() (map[string]any, error)
+}
+
+// typeBinder 由需要知道配置结构体类型的 Source 实现（如环境变量按字段绑定）
+type typeBinder interface {
+	bindType(t reflect.Type)
+}
+
+// keyOriginer 由能给出更细来源的 Source 实现（如具体的环境变量名）
+type keyOriginer interface {
+	origin(key string) string
+}
+
+// overlaySource 由需要参考下层已合并结果的 Source 实现（如按已有 map key 匹配环境变量）
+type overlaySource interface {
+	loadOver(base map[string]any) (map[string]any, error)
+}
+
+type fileSource struct {
+	path     string
+	optional bool
+}
+
+func File(path string) Source {
+	return &fileSource{path: path}
+}
+
+// OptionalFile 与 File 相同，但文件不存在时视为空配置，适合本地覆盖文件
+func OptionalFile(path string) Source {
+	return &fileSource{path: path, optional: true}
+}
+
+func (s *fileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *fileSource) Load() (map[string]any, error) {
+	if s.optional {
+		if _, err := os.Stat(s.path); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	v := viper.New()
+	v.SetConfigFile(s.path)
+	if ext := strings.TrimPrefix(filepath.Ext(s.path), "."); ext != "" {
+		v.SetConfigType(ext)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+type envSource struct {
+	prefix  string
+	cfgType reflect.Type
+	names   map[string]string
+}
+
+// Env 按配置结构体字段读取环境变量，变量名为 PREFIX_SECTION_KEY（大写）：
+//   - 切片：逗号分隔（LOG_OUTPUT_PATHS=stdout,/var/log/app.log）或 JSON 数组
+//   - map：JSON 对象（ISOLATION_ROUTES={"/ping":{"max_concurrent":10}}，与下层按条目合并），
+//     或按条目覆盖 PREFIX_KEY_<NAME>_FIELD（ISOLATION_ROUTES_API_USERS_MAX_QUEUE=20），
+//     NAME 与已有 key 去掉非字母数字后比较（/api/users -> API_USERS），无匹配时新增小写条目
+//   - time.Duration 与 Size 使用字符串（"200ms"、"100MB"）
+func Env(prefix string) Source {
+	return &envSource{prefix: prefix}
+}
+
+func (s *envSource) Name() string {
+	return "env"
+}
+
+func (s *envSource) bindType(t reflect.Type) {
+	s.cfgType = t
+}
+
+func (s *envSource) origin(key string) string {
+	for k := key; k != ""; {
+		if name, ok := s.names[k]; ok {
+			return "env:" + name
+		}
+		idx := strings.LastIndex(k, ".")
+		if idx < 0 {
+			break
+		}
+		k = k[:idx]
+	}
+	return "env:" + envName(s.prefix, key)
+}
+
+func (s *envSource) Load() (map[string]any, error) {
+	return s.loadOver(nil)
+}
+
+func (s *envSource) loadOver(base map[string]any) (map[string]any, error) {
+	if s.cfgType == nil {
+		return nil, errors.New("env source is not bound to a config type")
+	}
+	v := viper.New()
+	if s.prefix != "" {
+		v.SetEnvPrefix(s.prefix)
+	}
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	if err := bindEnvs(v, s.cfgType, ""); err != nil {
+		return nil, err
+	}
+	out := v.AllSettings()
+	s.names = make(map[string]string)
+	environ := os.Environ()
+	err := walkLeaves(s.cfgType, "", func(key string, field reflect.StructField) error {
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		name := envName(s.prefix, key)
+		raw, set := getPath(out, key)
+		if set {
+			s.names[key] = name
+		}
+		switch ft.Kind() {
+		case reflect.Slice:
+			if str, ok := raw.(string); ok && set {
+				val, err := parseEnvSlice(str)
+				if err != nil {
+					return fmt.Errorf("env %s: %w", name, err)
+				}
+				setPath(out, key, val)
+			}
+		case reflect.Map:
+			existing, _ := getPath(base, key)
+			if str, ok := raw.(string); ok && set {
+				var val map[string]any
+				if err := json.Unmarshal([]byte(str), &val); err != nil {
+					return fmt.Errorf("env %s: map value must be a JSON object: %w", name, err)
+				}
+				setPath(out, key, val)
+				existing = val
+			}
+			s.loadMapEntries(out, key, ft, existing, environ)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// loadMapEntries 扫描 PREFIX_KEY_<NAME>[_FIELD] 形式的环境变量并写入 map 条目
+func (s *envSource) loadMapEntries(out map[string]any, key string, mapType reflect.Type, existing any, environ []string) {
+	prefix := envName(s.prefix, key) + "_"
+	var fields []string
+	if elem := structElem(mapType); elem != nil {
+		_ = walkLeaves(elem, "", func(sub string, _ reflect.StructField) error {
+			fields = append(fields, sub)
+			return nil
+		})
+	}
+	known, _ := toStringMap(existing)
+
+	for _, kv := range environ {
+		name, val, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || val == "" {
+			continue
+		}
+		rest := name[len(prefix):]
+		entry, field := rest, ""
+		if len(fields) > 0 {
+			for _, f := range fields {
+				suffix := "_" + envName("", f)
+				if strings.HasSuffix(rest, suffix) && len(rest) > len(suffix) && len(f) > len(field) {
+					entry, field = rest[:len(rest)-len(suffix)], f
+				}
+			}
+			if field == "" {
+				continue
+			}
+		}
+		entryKey := strings.ToLower(entry)
+		for k := range known {
+			if envFriendly(k) == entry {
+				entryKey = k
+				break
+			}
+		}
+		full := key + "." + entryKey
+		if field != "" {
+			full += "." + field
+		}
+		setPathRaw(out, strings.Split(strings.ToLower(key), "."), entryKey, field, val)
+		s.names[full] = name
+	}
+}
+
+func parseEnvSlice(s string) ([]any, error) {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "[") {
+		var out []any
+		if err := json.Unmarshal([]byte(trimmed), &out); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+		return out, nil
+	}
+	parts := strings.Split(trimmed, ",")
+	out := make([]any, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// envFriendly 将任意 map key 转为环境变量可用的形式：大写，非字母数字折叠为单个下划线
+func envFriendly(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToUpper(s) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+type flagSource struct {
+	fs      *pflag.FlagSet
+	cfgType reflect.Type
+}
+
+// Flags 只取命令行中显式设置过的 flag，flag 名即配置 key（如 --http.addr），
+// 与配置结构体无关的 flag（如 --print-config）会被忽略
+func Flags(fs *pflag.FlagSet) Source {
+	return &flagSource{fs: fs}
+}
+
+func (s *flagSource) Name() string {
+	return "flag"
+}
+
+func (s *flagSource) bindType(t reflect.Type) {
+	s.cfgType = t
+}
+
+func (s *flagSource) origin(key string) string {
+	return "flag:--" + key
+}
+
+func (s *flagSource) Load() (map[string]any, error) {
+	out := make(map[string]any)
+	if s.fs == nil {
+		return out, nil
+	}
+	s.fs.Visit(func(f *pflag.Flag) {
+		if s.cfgType != nil {
+			if _, err := typeAt(s.cfgType, f.Name); err != nil {
+				return
+			}
+		}
+		var val any = f.Value.String()
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			val = sv.GetSlice()
+		}
+		setPath(out, f.Name, val)
+	})
+	return out, nil
+}
+
+type mapSource struct {
+	name   string
+	values map[string]any
+}
+
+// Map 提供内存中的配置层，key 可以是嵌套 map 或点分形式
+func Map(name string, values map[string]any) Source {
+	return &mapSource{name: name, values: values}
+}
+
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+func (s *mapSource) Load() (map[string]any, error) {
+	out := make(map[string]any)
+	for k, v := range s.values {
+		setPath(out, k, v)
+	}
+	return out, nil
+}
+
+// mergeLayers 按顺序合并各层配置，后面的层覆盖前面的层，并记录每个叶子 key 的来源
+func mergeLayers(layers []Source) (map[string]any, map[string]string, error) {
+	merged := make(map[string]any)
+	origins := make(map[string]string)
+	for _, src := range layers {
+		var (
+			values map[string]any
+			err    error
+		)
+		if o, ok := src.(overlaySource); ok {
+			values, err = o.loadOver(merged)
+		} else {
+			values, err = src.Load()
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("load %s: %w", src.Name(), err)
+		}
+		normalized := make(map[string]any)
+		for k, v := range values {
+			setPath(normalized, k, v)
+		}
+		mergeInto(merged, normalized)
+		for _, key := range leafKeys(normalized, "") {
+			for existing := range origins {
+				if strings.HasPrefix(existing, key+".") {
+					delete(origins, existing)
+				}
+			}
+			if o, ok := src.(keyOriginer); ok {
+				origins[key] = o.origin(key)
+			} else {
+				origins[key] = src.Name()
+			}
+		}
+	}
+	return merged, origins, nil
+}
+
+func mergeInto(dst, src map[string]any) {
+	for k, v := range src {
+		sm, ok := v.(map[string]any)
+		if !ok || len(sm) == 0 {
+			dst[k] = v
+			continue
+		}
+		dm, ok := dst[k].(map[string]any)
+		if !ok {
+			dm = make(map[string]any)
+			dst[k] = dm
+		}
+		mergeInto(dm, sm)
+	}
+}
+
+// setPath 将点分 key 写入嵌套 map，key 统一小写以与 viper 保持一致
+func setPath(m map[string]any, key string, val any) {
+	parts := strings.Split(strings.ToLower(key), ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[p] = next
+		}
+		m = next
+	}
+	last := parts[len(parts)-1]
+	if nested, ok := toStringMap(val); ok {
+		child, ok := m[last].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[last] = child
+		}
+		for k, v := range nested {
+			setPath(child, k, v)
+		}
+		return
+	}
+	m[last] = val
+}
+
+// setPathRaw 写入 map 条目，条目 key 原样保留（可能包含 "/" 等字符，但不能包含 "."）
+func setPathRaw(m map[string]any, parents []string, entryKey, field string, val any) {
+	for _, p := range parents {
+		next, ok := m[p].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[p] = next
+		}
+		m = next
+	}
+	if field == "" {
+		m[entryKey] = val
+		return
+	}
+	entry, ok := m[entryKey].(map[string]any)
+	if !ok {
+		entry = make(map[string]any)
+		m[entryKey] = entry
+	}
+	setPath(entry, field, val)
+}
+
+func getPath(m map[string]any, key string) (any, bool) {
+	var cur any = m
+	for _, p := range strings.Split(strings.ToLower(key), ".") {
+		cm, ok := toStringMap(cur)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = cm[p]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func toStringMap(val any) (map[string]any, bool) {
+	switch mv := val.(type) {
+	case map[string]any:
+		return mv, true
+	case map[any]any:
+		out := make(map[string]any, len(mv))
+		for k, v := range mv {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func leafKeys(m map[string]any, prefix string) []string {
+	var keys []string
+	for k, v := range m {
+		key := joinKey(prefix, k)
+		if sm, ok := v.(map[string]any); ok && len(sm) > 0 {
+			keys = append(keys, leafKeys(sm, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
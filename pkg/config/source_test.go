@@ -0,0 +1,274 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mini-jupiter/internal/testutil"
+	"mini-jupiter/pkg/isolation"
+	applog "mini-jupiter/pkg/log"
+	"mini-jupiter/pkg/metric"
+	"mini-jupiter/pkg/ratelimiter"
+
+	"github.com/spf13/pflag"
+)
+
+const exampleConfig = "../../examples/http-server/config.yaml"
+
+// appConfig 与 examples/http-server 中的 AppConfig 保持一致（main 包无法被导入）
+type appConfig struct {
+	App struct {
+		Name string `mapstructure:"name" yaml:"name" validate:"required"`
+		Env  string `mapstructure:"env" yaml:"env" default:"dev"`
+	} `mapstructure:"app" yaml:"app"`
+	HTTP struct {
+		Addr string `mapstructure:"addr" yaml:"addr" default:":8080" validate:"required"`
+	} `mapstructure:"http" yaml:"http"`
+	Admin struct {
+		Addr  string `mapstructure:"addr" yaml:"addr" default:"127.0.0.1:8081" validate:"required"`
+		Token Secret `mapstructure:"token" yaml:"token"`
+	} `mapstructure:"admin" yaml:"admin"`
+	Log        applog.Config      `mapstructure:"log" yaml:"log"`
+	Metric     metric.Config      `mapstructure:"metric" yaml:"metric"`
+	RateLimit  ratelimiter.Config `mapstructure:"ratelimit" yaml:"ratelimit"`
+	Isolation  isolation.Config   `mapstructure:"isolation" yaml:"isolation"`
+	Middleware struct {
+		Recovery bool `mapstructure:"recovery" yaml:"recovery"`
+		TraceID  bool `mapstructure:"trace_id" yaml:"trace_id"`
+		Logging  bool `mapstructure:"logging" yaml:"logging"`
+	} `mapstructure:"middleware" yaml:"middleware"`
+}
+
+func loadExample(t *testing.T, env map[string]string) (*appConfig, *Manager[appConfig]) {
+	t.Helper()
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	var cfg appConfig
+	m, err := Load(exampleConfig, &cfg)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return &cfg, m
+}
+
+func TestEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		check  func(t *testing.T, cfg *appConfig)
+		origin map[string]string
+	}{
+		{
+			name: "scalar",
+			env:  map[string]string{"HTTP_ADDR": ":9090", "RATELIMIT_RATE": "2.5"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.HTTP.Addr, ":9090")
+				testutil.Equal(t, cfg.RateLimit.Rate, 2.5)
+			},
+			origin: map[string]string{"http.addr": "env:HTTP_ADDR"},
+		},
+		{
+			name: "slice comma separated",
+			env:  map[string]string{"LOG_OUTPUT_PATHS": "stdout, /var/log/app.log"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Log.OutputPaths, []string{"stdout", "/var/log/app.log"})
+			},
+			origin: map[string]string{"log.output_paths": "env:LOG_OUTPUT_PATHS"},
+		},
+		{
+			name: "slice JSON array",
+			env:  map[string]string{"LOG_OUTPUT_PATHS": `["stdout","/tmp/a,b.log"]`},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Log.OutputPaths, []string{"stdout", "/tmp/a,b.log"})
+			},
+		},
+		{
+			name: "slice replaces file value",
+			env:  map[string]string{"LOG_REDACT_KEYS": "api_key"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Log.Redact.Keys, []string{"api_key"})
+			},
+		},
+		{
+			name: "map JSON object merges with file entries",
+			env:  map[string]string{"ISOLATION_ROUTES": `{"/health":{"max_concurrent":5,"max_queue":1}}`},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Isolation.Routes["/health"], isolation.RouteConfig{MaxConcurrent: 5, MaxQueue: 1, WaitTimeoutMs: 50})
+				testutil.Equal(t, cfg.Isolation.Routes["/ping"].MaxConcurrent, 200)
+			},
+		},
+		{
+			name: "map entry field by env-friendly name",
+			env:  map[string]string{"ISOLATION_ROUTES_API_USERS_MAX_QUEUE": "20"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Isolation.Routes["/api/users"], isolation.RouteConfig{MaxConcurrent: 50, MaxQueue: 20, WaitTimeoutMs: 100})
+			},
+			origin: map[string]string{"isolation.routes./api/users.max_queue": "env:ISOLATION_ROUTES_API_USERS_MAX_QUEUE"},
+		},
+		{
+			name: "map entry added when no existing key matches",
+			env:  map[string]string{"ISOLATION_ROUTES_ORDERS_MAX_CONCURRENT": "3"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Isolation.Routes["orders"], isolation.RouteConfig{MaxConcurrent: 3, WaitTimeoutMs: 50})
+				testutil.Equal(t, len(cfg.Isolation.Routes), 4)
+			},
+		},
+		{
+			name: "map of scalars",
+			env:  map[string]string{"LOG_MODULES_POOL": "debug", "LOG_MODULES": `{"http":"warn"}`},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Log.Modules, map[string]string{"pool": "debug", "isolation": "info", "http": "warn"})
+			},
+		},
+		{
+			name: "duration",
+			env:  map[string]string{"LOG_ASYNC_FLUSH_INTERVAL": "250ms"},
+			check: func(t *testing.T, cfg *appConfig) {
+				testutil.Equal(t, cfg.Log.Async.FlushInterval, 250*time.Millisecond)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, m := loadExample(t, tt.env)
+			tt.check(t, cfg)
+			for key, want := range tt.origin {
+				testutil.Equal(t, m.Origin(key), want)
+			}
+		})
+	}
+}
+
+func TestEnvOverrideErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{"invalid JSON array", map[string]string{"LOG_OUTPUT_PATHS": `["stdout"`}},
+		{"map is not a JSON object", map[string]string{"ISOLATION_ROUTES": "/ping"}},
+		{"invalid duration", map[string]string{"LOG_ASYNC_FLUSH_INTERVAL": "soon"}},
+		{"validation still applies", map[string]string{"LOG_ASYNC_OVERFLOW": "spill"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var cfg appConfig
+			if _, err := Load(exampleConfig, &cfg); err == nil {
+				t.Fatal("Load succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestEnvSize(t *testing.T) {
+	// 示例配置中没有 Size 字段，在其基础上追加一个
+	type config struct {
+		appConfig `mapstructure:",squash"`
+		Upload    struct {
+			MaxBody Size `mapstructure:"max_body" yaml:"max_body" default:"1MB"`
+		} `mapstructure:"upload" yaml:"upload"`
+	}
+	tests := []struct {
+		env  string
+		want Size
+	}{
+		{"", MB},
+		{"512KB", 512 * KB},
+		{"1.5GiB", GB + 512*MB},
+		{"2048", 2048},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv("UPLOAD_MAX_BODY", tt.env)
+			}
+			var cfg config
+			if _, err := Load(exampleConfig, &cfg); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			testutil.Equal(t, cfg.Upload.MaxBody, tt.want)
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Size
+		wantErr bool
+	}{
+		{"100", 100, false},
+		{"10b", 10, false},
+		{"4k", 4 * KB, false},
+		{"100MB", 100 * MB, false},
+		{" 2 gib ", 2 * GB, false},
+		{"", 0, true},
+		{"-1MB", 0, true},
+		{"ten", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+	testutil.Equal(t, (512 * KB).String(), "512KB")
+	testutil.Equal(t, Size(1500).String(), "1500B")
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	t.Setenv("HTTP_ADDR", ":7000")
+	var cfg appConfig
+	m, err := Load(exampleConfig, &cfg, WithSources[appConfig](
+		Map("override", map[string]any{"http.addr": ":6000", "app.env": "test"}),
+		Env(""),
+	))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	testutil.Equal(t, cfg.HTTP.Addr, ":7000")
+	testutil.Equal(t, cfg.App.Env, "test")
+	testutil.Equal(t, cfg.App.Name, "mini-jupiter")
+	testutil.Equal(t, m.Origin("app.env"), "override")
+	testutil.Equal(t, m.Origin("app.name"), "file:"+exampleConfig)
+}
+
+func TestFlagsAndOptionalFile(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("http.addr", "", "")
+	fs.String("log.level", "", "")
+	fs.StringSlice("log.output_paths", nil, "")
+	fs.Bool("print-config", false, "")
+	if err := fs.Parse([]string{"--http.addr=:5000", "--log.output_paths=stdout,stderr", "--print-config"}); err != nil {
+		t.Fatal(err)
+	}
+	local := writeConfig(t, "app:\n  env: local\nhttp:\n  addr: ':4000'\n")
+
+	var cfg appConfig
+	m, err := Load(exampleConfig, &cfg, WithSources[appConfig](
+		OptionalFile(filepath.Join(t.TempDir(), "missing.yaml")),
+		OptionalFile(local),
+		Flags(fs),
+	))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	testutil.Equal(t, cfg.App.Env, "local")
+	testutil.Equal(t, cfg.HTTP.Addr, ":5000")
+	testutil.Equal(t, cfg.Log.Level, "info")
+	testutil.Equal(t, cfg.Log.OutputPaths, []string{"stdout", "stderr"})
+	testutil.Equal(t, m.Origin("http.addr"), "flag:--http.addr")
+	testutil.Equal(t, m.Origin("app.env"), "file:"+local)
+
+	if _, err := Load(exampleConfig, &cfg, WithSources[appConfig](File(filepath.Join(t.TempDir(), "missing.yaml")))); err == nil {
+		t.Error("Load succeeded with a missing required file")
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
+)
+
+// Participant 参与两阶段重载：所有参与者 Prepare 通过后依次 Commit；
+// 任一 Prepare 返回错误即否决本次重载，任一 Commit 失败则对已提交者按逆序
+// 以 Commit(new, old) 回滚到旧快照，Current() 保持不变
+type Participant[T any] interface {
+	Name() string
+	Prepare(old, new *T) error
+	Commit(old, new *T) error
+}
+
+// ParticipantFuncs 用函数构造 Participant，Prepare 可为空
+type ParticipantFuncs[T any] struct {
+	ID          string
+	PrepareFunc func(old, new *T) error
+	CommitFunc  func(old, new *T) error
+}
+
+func (p ParticipantFuncs[T]) Name() string {
+	return p.ID
+}
+
+func (p ParticipantFuncs[T]) Prepare(old, new *T) error {
+	if p.PrepareFunc == nil {
+		return nil
+	}
+	return p.PrepareFunc(old, new)
+}
+
+func (p ParticipantFuncs[T]) Commit(old, new *T) error {
+	if p.CommitFunc == nil {
+		return nil
+	}
+	return p.CommitFunc(old, new)
+}
+
+type ReloadError struct {
+	Phase       string
+	Participant string
+	Err         error
+}
+
+func (e *ReloadError) Error() string {
+	return fmt.Sprintf("%s rejected by %s: %v", e.Phase, e.Participant, e.Err)
+}
+
+func (e *ReloadError) Unwrap() error {
+	return e.Err
+}
+
+func WithParticipant[T any](p Participant[T]) Option[T] {
+	return func(m *Manager[T]) {
+		if p != nil {
+			m.parts = append(m.parts, p)
+		}
+	}
+}
+
+// Join 在运行时加入重载事务，按加入顺序提交
+func (m *Manager[T]) Join(p Participant[T]) {
+	if p == nil {
+		return
+	}
+	m.partMu.Lock()
+	defer m.partMu.Unlock()
+	m.parts = append(m.parts, p)
+}
+
+func (m *Manager[T]) apply(old, cfg *T) error {
+	m.partMu.RLock()
+	parts := append([]Participant[T](nil), m.parts...)
+	m.partMu.RUnlock()
+
+	for _, p := range parts {
+		if err := p.Prepare(old, cfg); err != nil {
+			return &ReloadError{Phase: "prepare", Participant: p.Name(), Err: err}
+		}
+	}
+	for i, p := range parts {
+		err := p.Commit(old, cfg)
+		if err == nil {
+			continue
+		}
+		commitErr := &ReloadError{Phase: "commit", Participant: p.Name(), Err: err}
+		var rollbackErrs []error
+		for j := i - 1; j >= 0; j-- {
+			if rerr := parts[j].Commit(cfg, old); rerr != nil {
+				applog.Named("config").Error("config rollback failed",
+					zap.String("participant", parts[j].Name()),
+					zap.Error(rerr),
+				)
+				rollbackErrs = append(rollbackErrs, fmt.Errorf("rollback %s: %w", parts[j].Name(), rerr))
+			}
+		}
+		if len(rollbackErrs) > 0 {
+			return errors.Join(append([]error{commitErr}, rollbackErrs...)...)
+		}
+		return commitErr
+	}
+	return nil
+}
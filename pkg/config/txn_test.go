@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+// recorder 记录参与者的调用顺序，failOn 指定在哪个阶段返回错误
+type recorder struct {
+	calls  *[]string
+	name   string
+	failOn string
+	rate   float64
+}
+
+func (r *recorder) Name() string {
+	return r.name
+}
+
+func (r *recorder) Prepare(_, _ *appConfig) error {
+	*r.calls = append(*r.calls, r.name+".prepare")
+	if r.failOn == "prepare" {
+		return errors.New("refused")
+	}
+	return nil
+}
+
+func (r *recorder) Commit(_, new *appConfig) error {
+	*r.calls = append(*r.calls, r.name+".commit")
+	if r.failOn == "commit" && new.RateLimit.Rate != r.rate {
+		return errors.New("apply failed")
+	}
+	r.rate = new.RateLimit.Rate
+	return nil
+}
+
+func TestReloadTransaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		failA     string
+		failB     string
+		wantCalls []string
+		wantPhase string
+		wantRates [2]float64
+	}{
+		{
+			name:      "all commit",
+			wantCalls: []string{"a.prepare", "b.prepare", "a.commit", "b.commit"},
+			wantRates: [2]float64{2, 2},
+		},
+		{
+			name:      "prepare veto",
+			failB:     "prepare",
+			wantCalls: []string{"a.prepare", "b.prepare"},
+			wantPhase: "prepare",
+			wantRates: [2]float64{1, 1},
+		},
+		{
+			name:      "commit failure rolls back committed participants",
+			failB:     "commit",
+			wantCalls: []string{"a.prepare", "b.prepare", "a.commit", "b.commit", "a.commit"},
+			wantPhase: "commit",
+			wantRates: [2]float64{1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]any{"ratelimit.rate": 1}
+			var (
+				cfg   appConfig
+				calls []string
+				errs  []error
+			)
+			a := &recorder{calls: &calls, name: "a", failOn: tt.failA, rate: 1}
+			b := &recorder{calls: &calls, name: "b", failOn: tt.failB, rate: 1}
+			m, err := Load(exampleConfig, &cfg,
+				WithSources[appConfig](Map("test", values)),
+				WithParticipant[appConfig](a),
+				WithOnReloadError[appConfig](func(err error) { errs = append(errs, err) }),
+			)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			m.Join(b)
+
+			values["ratelimit.rate"] = 2
+			m.reload("test")
+			testutil.Equal(t, calls, tt.wantCalls)
+			testutil.Equal(t, [2]float64{a.rate, b.rate}, tt.wantRates)
+			if tt.wantPhase == "" {
+				testutil.Equal(t, len(errs), 0)
+				testutil.Equal(t, m.Current().RateLimit.Rate, 2.0)
+				return
+			}
+			testutil.Equal(t, m.Current().RateLimit.Rate, 1.0)
+			var rerr *ReloadError
+			if len(errs) != 1 || !errors.As(errs[0], &rerr) {
+				t.Fatalf("reload errors = %v, want one *ReloadError", errs)
+			}
+			testutil.Equal(t, rerr.Phase, tt.wantPhase)
+			testutil.Equal(t, rerr.Participant, "b")
+		})
+	}
+}
+
+func TestReloadTransactionRollbackFailure(t *testing.T) {
+	values := map[string]any{"ratelimit.rate": 1}
+	var (
+		cfg  appConfig
+		errs []error
+	)
+	m, err := Load(exampleConfig, &cfg,
+		WithSources[appConfig](Map("test", values)),
+		WithParticipant[appConfig](ParticipantFuncs[appConfig]{
+			ID: "sticky",
+			CommitFunc: func(old, new *appConfig) error {
+				if new.RateLimit.Rate == 1 {
+					return errors.New("cannot roll back")
+				}
+				return nil
+			},
+		}),
+		WithParticipant[appConfig](ParticipantFuncs[appConfig]{
+			ID:         "broken",
+			CommitFunc: func(_, _ *appConfig) error { return errors.New("apply failed") },
+		}),
+		WithOnReloadError[appConfig](func(err error) { errs = append(errs, err) }),
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	values["ratelimit.rate"] = 2
+	m.reload("test")
+	if len(errs) != 1 {
+		t.Fatalf("got %d reload errors, want 1", len(errs))
+	}
+	for _, want := range []string{"commit rejected by broken", "rollback sticky: cannot roll back"} {
+		if !strings.Contains(errs[0].Error(), want) {
+			t.Errorf("error %q does not contain %q", errs[0], want)
+		}
+	}
+	testutil.Equal(t, m.Current().RateLimit.Rate, 1.0)
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator 由配置结构体（或其嵌套字段）实现，用于标签无法表达的跨字段校验
+type Validator interface {
+	Validate() error
+}
+
+type FieldError struct {
+	Field string
+	Rule  string
+	Err   string
+}
+
+func (e FieldError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Err)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Field, e.Err, e.Rule)
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// validateStruct 按 validate 标签校验 cfg，并调用各层实现的 Validate() 方法
+func validateStruct(cfg any) error {
+	var errs []FieldError
+	walkValidate(reflect.ValueOf(cfg), "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func walkValidate(v reflect.Value, path string, errs *[]FieldError) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			key, skip := fieldKey(field)
+			if skip {
+				continue
+			}
+			if path != "" {
+				key = path + "." + key
+			}
+			fv := v.Field(i)
+			if tag := field.Tag.Get("validate"); tag != "" {
+				checkRules(fv, key, tag, errs)
+			}
+			walkValidate(fv, key, errs)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkValidate(iter.Value(), fmt.Sprintf("%s.%v", path, iter.Key()), errs)
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkValidate(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		return
+	default:
+		return
+	}
+
+	if v.CanAddr() {
+		if val, ok := v.Addr().Interface().(Validator); ok {
+			appendValidatorError(val, path, errs)
+			return
+		}
+	}
+	if val, ok := v.Interface().(Validator); ok {
+		appendValidatorError(val, path, errs)
+	}
+}
+
+func appendValidatorError(val Validator, path string, errs *[]FieldError) {
+	if err := val.Validate(); err != nil {
+		field := path
+		if field == "" {
+			field = "<root>"
+		}
+		*errs = append(*errs, FieldError{Field: field, Err: err.Error()})
+	}
+}
+
+func checkRules(v reflect.Value, key, tag string, errs *[]FieldError) {
+	rules := strings.Split(tag, ",")
+	for _, rule := range rules {
+		if rule == "omitempty" && v.IsZero() {
+			return
+		}
+	}
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		var msg string
+		switch name {
+		case "", "omitempty":
+			continue
+		case "required":
+			if v.IsZero() {
+				msg = "is required"
+			}
+		case "min", "max":
+			msg = checkBound(v, name, arg)
+		case "oneof":
+			msg = checkOneOf(v, arg)
+		default:
+			msg = "unknown validation rule"
+		}
+		if msg != "" {
+			*errs = append(*errs, FieldError{Field: key, Rule: strings.TrimSpace(rule), Err: msg})
+		}
+	}
+}
+
+func checkBound(v reflect.Value, rule, arg string) string {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return "invalid rule argument"
+	}
+	var (
+		val  float64
+		unit string
+	)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		val = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		val = float64(v.Len())
+		unit = "length "
+	default:
+		return "rule not supported for " + v.Kind().String()
+	}
+	if rule == "min" && val < limit {
+		return fmt.Sprintf("%smust be >= %s", unit, arg)
+	}
+	if rule == "max" && val > limit {
+		return fmt.Sprintf("%smust be <= %s", unit, arg)
+	}
+	return ""
+}
+
+func checkOneOf(v reflect.Value, arg string) string {
+	allowed := strings.Fields(arg)
+	var got string
+	switch v.Kind() {
+	case reflect.String:
+		got = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = strconv.FormatUint(v.Uint(), 10)
+	default:
+		return "rule not supported for " + v.Kind().String()
+	}
+	for _, a := range allowed {
+		if got == a {
+			return ""
+		}
+	}
+	return fmt.Sprintf("must be one of [%s]", strings.Join(allowed, " "))
+}
@@ -1,9 +1,173 @@
 package errors
 
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
 const (
-	CodeOK            = 0
-	CodeBadRequest    = 400
+	CodeOK              = 0
+	CodeBadRequest      = 400
+	CodeUnauthorized    = 401
 	CodeTooManyRequests = 429
-	CodeNotFound      = 404
-	CodeInternalError = 500
+	CodeNotFound        = 404
+	CodeInternalError   = 500
+)
+
+// Status 是与 gRPC 一致的规范状态码（google.golang.org/grpc/codes），不依赖 gRPC 包
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusCanceled
+	StatusUnknown
+	StatusInvalidArgument
+	StatusDeadlineExceeded
+	StatusNotFound
+	StatusAlreadyExists
+	StatusPermissionDenied
+	StatusResourceExhausted
+	StatusFailedPrecondition
+	StatusAborted
+	StatusOutOfRange
+	StatusUnimplemented
+	StatusInternal
+	StatusUnavailable
+	StatusDataLoss
+	StatusUnauthenticated
+)
+
+var statusNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND",
+	"ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED",
+	"OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+func (s Status) String() string {
+	if s >= 0 && int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// statusHTTP 是规范状态码到 HTTP 状态码的默认映射（与 grpc-gateway 一致）
+var statusHTTP = map[Status]int{
+	StatusOK:                 http.StatusOK,
+	StatusCanceled:           499,
+	StatusUnknown:            http.StatusInternalServerError,
+	StatusInvalidArgument:    http.StatusBadRequest,
+	StatusDeadlineExceeded:   http.StatusGatewayTimeout,
+	StatusNotFound:           http.StatusNotFound,
+	StatusAlreadyExists:      http.StatusConflict,
+	StatusPermissionDenied:   http.StatusForbidden,
+	StatusResourceExhausted:  http.StatusTooManyRequests,
+	StatusFailedPrecondition: http.StatusBadRequest,
+	StatusAborted:            http.StatusConflict,
+	StatusOutOfRange:         http.StatusBadRequest,
+	StatusUnimplemented:      http.StatusNotImplemented,
+	StatusInternal:           http.StatusInternalServerError,
+	StatusUnavailable:        http.StatusServiceUnavailable,
+	StatusDataLoss:           http.StatusInternalServerError,
+	StatusUnauthenticated:    http.StatusUnauthorized,
+}
+
+// CodeInfo 描述一个错误码：默认消息、HTTP 状态码、规范状态码与是否可重试；
+// 非 CodeOK 的错误码未填写 Status 时视为 StatusUnknown，HTTPStatus 为 0 时按 Status 推导
+type CodeInfo struct {
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	HTTPStatus int    `json:"http_status"`
+	Status     Status `json:"status"`
+	Retryable  bool   `json:"retryable"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[int]CodeInfo)
 )
+
+func init() {
+	MustRegister(CodeInfo{Code: CodeOK, Message: "ok", Status: StatusOK})
+	MustRegister(CodeInfo{Code: CodeBadRequest, Message: "bad request", Status: StatusInvalidArgument})
+	MustRegister(CodeInfo{Code: CodeUnauthorized, Message: "unauthorized", Status: StatusUnauthenticated})
+	MustRegister(CodeInfo{Code: CodeNotFound, Message: "not found", Status: StatusNotFound})
+	MustRegister(CodeInfo{Code: CodeTooManyRequests, Message: "too many requests", Status: StatusResourceExhausted, Retryable: true})
+	MustRegister(CodeInfo{Code: CodeInternalError, Message: "internal error", Status: StatusInternal})
+}
+
+// Register 登记错误码，同一错误码重复登记返回错误
+func Register(info CodeInfo) error {
+	// Status 零值即 StatusOK，错误码漏填时不能因此映射为 HTTP 200
+	if info.Code != CodeOK && info.Status == StatusOK {
+		info.Status = StatusUnknown
+	}
+	if info.HTTPStatus == 0 {
+		info.HTTPStatus = http.StatusInternalServerError
+		if s, ok := statusHTTP[info.Status]; ok {
+			info.HTTPStatus = s
+		}
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if prev, ok := registry[info.Code]; ok {
+		return fmt.Errorf("error code %d already registered (%q)", info.Code, prev.Message)
+	}
+	registry[info.Code] = info
+	return nil
+}
+
+// MustRegister 登记错误码并返回该码，重复登记时 panic，便于在包级变量中声明：
+//
+//	var CodeUserBanned = apperr.MustRegister(apperr.CodeInfo{Code: 10403, Message: "user banned", Status: apperr.StatusPermissionDenied})
+func MustRegister(info CodeInfo) int {
+	if err := Register(info); err != nil {
+		panic(err)
+	}
+	return info.Code
+}
+
+// Lookup 返回已登记的错误码信息
+func Lookup(code int) (CodeInfo, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registry[code]
+	return info, ok
+}
+
+// Codes 返回按错误码排序的全部登记信息
+func Codes() []CodeInfo {
+	registryMu.RLock()
+	out := make([]CodeInfo, 0, len(registry))
+	for _, info := range registry {
+		out = append(out, info)
+	}
+	registryMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+// infoOf 返回 err 对应的错误码信息，未登记的错误码与非 *Error 错误按 CodeInternalError 处理
+func infoOf(err error) CodeInfo {
+	code := CodeOf(err)
+	if info, ok := Lookup(code); ok {
+		return info
+	}
+	info, _ := Lookup(CodeInternalError)
+	info.Code = code
+	return info
+}
+
+// GRPCStatus 返回 err 对应的规范状态码
+func GRPCStatus(err error) Status {
+	return infoOf(err).Status
+}
+
+// IsRetryable 判断 err 的错误码是否登记为可重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	return infoOf(err).Retryable
+}
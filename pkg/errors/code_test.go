@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+var (
+	codeUserBanned = MustRegister(CodeInfo{Code: 10403, Message: "user banned", Status: StatusPermissionDenied})
+	codeBusy       = MustRegister(CodeInfo{Code: 10503, Message: "busy", Status: StatusUnavailable, Retryable: true})
+	codeTeapot     = MustRegister(CodeInfo{Code: 10418, Message: "teapot", HTTPStatus: http.StatusTeapot, Status: StatusUnknown})
+	codeNoStatus   = MustRegister(CodeInfo{Code: 10001, Message: "no status"})
+)
+
+func TestRegistry(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		status    int
+		grpc      Status
+		retryable bool
+	}{
+		{"nil", nil, http.StatusOK, StatusOK, false},
+		{"builtin", New(CodeNotFound, ""), http.StatusNotFound, StatusNotFound, false},
+		{"builtin retryable", New(CodeTooManyRequests, ""), http.StatusTooManyRequests, StatusResourceExhausted, true},
+		{"http status from status", New(codeUserBanned, ""), http.StatusForbidden, StatusPermissionDenied, false},
+		{"explicit http status", New(codeTeapot, ""), http.StatusTeapot, StatusUnknown, false},
+		{"missing status", New(codeNoStatus, ""), http.StatusInternalServerError, StatusUnknown, false},
+		{"wrapped", fmt.Errorf("call: %w", New(codeBusy, "")), http.StatusServiceUnavailable, StatusUnavailable, true},
+		{"unregistered code", New(10999, "custom"), http.StatusInternalServerError, StatusInternal, false},
+		{"plain error", io.EOF, http.StatusInternalServerError, StatusInternal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.Equal(t, HTTPStatus(tt.err), tt.status)
+			if tt.err != nil {
+				testutil.Equal(t, GRPCStatus(tt.err), tt.grpc)
+			}
+			testutil.Equal(t, IsRetryable(tt.err), tt.retryable)
+		})
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	if err := Register(CodeInfo{Code: codeUserBanned, Message: "again"}); err == nil {
+		t.Error("duplicate Register succeeded")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate MustRegister did not panic")
+		}
+	}()
+	MustRegister(CodeInfo{Code: CodeNotFound})
+}
+
+func TestLookupAndCodes(t *testing.T) {
+	info, ok := Lookup(codeBusy)
+	testutil.Equal(t, ok, true)
+	testutil.Equal(t, info.HTTPStatus, http.StatusServiceUnavailable)
+	_, ok = Lookup(10999)
+	testutil.Equal(t, ok, false)
+
+	codes := Codes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Fatalf("Codes not sorted: %d before %d", codes[i-1].Code, codes[i].Code)
+		}
+	}
+	testutil.Equal(t, codes[0].Code, CodeOK)
+}
+
+func TestStatusString(t *testing.T) {
+	testutil.Equal(t, StatusResourceExhausted.String(), "RESOURCE_EXHAUSTED")
+	testutil.Equal(t, StatusUnauthenticated.String(), "UNAUTHENTICATED")
+	testutil.Equal(t, Status(99).String(), "Status(99)")
+}
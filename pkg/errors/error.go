@@ -1,13 +1,39 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
 )
 
 type Error struct {
 	Code    int
 	Message string
 	Cause   error
+	Details Details
+	// Params 为消息模板参数，渲染时替换消息中的 {name}
+	Params map[string]any
+	stack  stack
+	// defaultMsg 表示 Message 取自登记表的默认消息（New/Wrap 时 msg 为空），只有这种消息会按语言替换
+	defaultMsg bool
+}
+
+// Details 是错误的结构化附加信息：Metadata 为任意键值，Violations 为字段级校验失败
+type Details struct {
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Violations []FieldViolation  `json:"violations,omitempty"`
+}
+
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+func (d Details) IsZero() bool {
+	return len(d.Metadata) == 0 && len(d.Violations) == 0
 }
 
 func (e *Error) Error() string {
@@ -17,12 +43,137 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%d:%s", e.Code, e.Message)
 }
 
+// Unwrap 使标准库 errors.Is/As 可以穿过 Error 访问 Cause
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is 按错误码匹配：errors.Is(err, New(CodeNotFound, "")) 对任意 404 错误成立
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t != nil && t.Code == e.Code
+}
+
+// WithDetail 返回附加了键值信息的副本，原错误（如包级变量）不受影响
+func (e *Error) WithDetail(key, value string) *Error {
+	c := e.clone()
+	c.Details.Metadata = make(map[string]string, len(e.Details.Metadata)+1)
+	for k, v := range e.Details.Metadata {
+		c.Details.Metadata[k] = v
+	}
+	c.Details.Metadata[key] = value
+	return c
+}
+
+// WithViolation 返回附加了字段校验失败信息的副本
+func (e *Error) WithViolation(field, description string) *Error {
+	c := e.clone()
+	c.Details.Violations = append(append([]FieldViolation(nil), e.Details.Violations...),
+		FieldViolation{Field: field, Description: description})
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	return &c
+}
+
+// Format 支持 %s、%v、%q；%+v 输出错误码、消息、详情与调用栈，并逐层输出 Cause 链
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprintf(s, "%d:%s", e.Code, e.Message)
+			e.Details.format(s)
+			e.stack.format(s)
+			if e.Cause != nil {
+				fmt.Fprintf(s, "\ncaused by: %+v", e.Cause)
+			}
+			return
+		}
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func (d Details) format(w io.Writer) {
+	keys := make([]string, 0, len(d.Metadata))
+	for k := range d.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "\n  %s=%s", k, d.Metadata[k])
+	}
+	for _, v := range d.Violations {
+		fmt.Fprintf(w, "\n  violation %s: %s", v.Field, v.Description)
+	}
+}
+
+// Wrap 与 New 在 msg 为空时使用错误码登记的默认消息
 func Wrap(code int, msg string, err error) *Error {
-	return &Error{Code: code, Message: msg, Cause: err}
+	return &Error{Code: code, Message: defaultMessage(code, msg), Cause: err, stack: callers(), defaultMsg: msg == ""}
 }
 
 func New(code int, msg string) *Error {
-	return &Error{Code: code, Message: msg}
+	return &Error{Code: code, Message: defaultMessage(code, msg), stack: callers(), defaultMsg: msg == ""}
+}
+
+func defaultMessage(code int, msg string) string {
+	if msg != "" {
+		return msg
+	}
+	if info, ok := Lookup(code); ok {
+		return info.Message
+	}
+	return msg
+}
+
+// CodeOf 返回错误链中第一个 *Error 的错误码，nil 返回 CodeOK，其他错误返回 CodeInternalError
+func CodeOf(err error) int {
+	if err == nil {
+		return CodeOK
+	}
+	if e, ok := asError(err); ok {
+		return e.Code
+	}
+	return CodeInternalError
+}
+
+func asError(err error) (*Error, bool) {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// stack 保存 New/Wrap 调用处的调用栈
+type stack []uintptr
+
+func callers() stack {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+	return stack(pcs[:n])
+}
+
+func (st stack) format(w io.Writer) {
+	if len(st) == 0 {
+		return
+	}
+	frames := runtime.CallersFrames(st)
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			fmt.Fprintf(w, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+		}
+		if !more {
+			return
+		}
+	}
 }
 
 type Reporter func(code int)
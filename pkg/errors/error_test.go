@@ -0,0 +1,91 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+func TestUnwrapAndIs(t *testing.T) {
+	root := io.ErrUnexpectedEOF
+	err := fmt.Errorf("handler: %w", Wrap(CodeNotFound, "user not found", root))
+
+	if !stderrors.Is(err, root) {
+		t.Error("errors.Is does not reach the cause")
+	}
+	if !stderrors.Is(err, New(CodeNotFound, "")) {
+		t.Error("errors.Is does not match by code")
+	}
+	if stderrors.Is(err, New(CodeBadRequest, "user not found")) {
+		t.Error("errors.Is matched a different code")
+	}
+	if stderrors.Is(err, (*Error)(nil)) {
+		t.Error("errors.Is matched a typed nil *Error")
+	}
+	var e *Error
+	if !stderrors.As(err, &e) || e.Message != "user not found" {
+		t.Errorf("errors.As = %v", e)
+	}
+
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, CodeOK},
+		{err, CodeNotFound},
+		{root, CodeInternalError},
+		{New(CodeTooManyRequests, ""), CodeTooManyRequests},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("CodeOf(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultMessage(t *testing.T) {
+	testutil.Equal(t, New(CodeNotFound, "").Message, "not found")
+	testutil.Equal(t, New(CodeNotFound, "no such user").Message, "no such user")
+	testutil.Equal(t, New(12345, "").Message, "")
+}
+
+func TestDetailsCopy(t *testing.T) {
+	base := New(CodeBadRequest, "")
+	withDetail := base.WithDetail("request_id", "r1").WithDetail("shard", "2")
+	withViolation := withDetail.WithViolation("name", "required")
+
+	testutil.Equal(t, base.Details.IsZero(), true)
+	testutil.Equal(t, len(withDetail.Details.Metadata), 2)
+	testutil.Equal(t, len(withDetail.Details.Violations), 0)
+	testutil.Equal(t, withViolation.Details.Violations[0], FieldViolation{Field: "name", Description: "required"})
+	testutil.Equal(t, withViolation.Details.Metadata["shard"], "2")
+}
+
+func TestFormat(t *testing.T) {
+	cause := New(CodeInternalError, "db down")
+	err := Wrap(CodeNotFound, "user not found", cause).WithDetail("id", "42").WithViolation("id", "unknown")
+
+	testutil.Equal(t, fmt.Sprintf("%s", err), "404:user not found: 500:db down")
+	testutil.Equal(t, fmt.Sprintf("%v", err), "404:user not found: 500:db down")
+	testutil.Equal(t, fmt.Sprintf("%q", err), `"404:user not found: 500:db down"`)
+	testutil.Equal(t, New(CodeBadRequest, "").Error(), "400:bad request")
+
+	verbose := fmt.Sprintf("%+v", err)
+	for _, want := range []string{
+		"404:user not found\n  id=42\n  violation id: unknown\n",
+		"errors.TestFormat\n",
+		"error_test.go:",
+		"\ncaused by: 500:db down\n",
+	} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("%%+v missing %q:\n%s", want, verbose)
+		}
+	}
+	if strings.Contains(verbose, "runtime.") {
+		t.Errorf("%%+v includes runtime frames:\n%s", verbose)
+	}
+}
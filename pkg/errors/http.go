@@ -8,53 +8,52 @@ import (
 	applog "mini-jupiter/pkg/log"
 )
 
+// HTTPStatus 按错误码登记表返回 HTTP 状态码，未登记的错误码返回 500
 func HTTPStatus(err error) int {
 	if err == nil {
 		return http.StatusOK
 	}
-	if e, ok := err.(*Error); ok {
-		switch e.Code {
-		case CodeBadRequest:
-			return http.StatusBadRequest
-		case CodeTooManyRequests:
-			return http.StatusTooManyRequests
-		case CodeNotFound:
-			return http.StatusNotFound
-		default:
-			return http.StatusInternalServerError
-		}
-	}
-	return http.StatusInternalServerError
+	return infoOf(err).HTTPStatus
 }
 
 type Response struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	TraceID string `json:"trace_id,omitempty"`
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	TraceID string   `json:"trace_id,omitempty"`
+	Details *Details `json:"details,omitempty"`
 }
 
 func WriteHTTP(w http.ResponseWriter, err error) {
 	WriteHTTPWithContext(context.Background(), w, err)
 }
 
+// WriteHTTPWithContext 写出 JSON 错误响应，消息按 ctx 上的语言（WithLocale）本地化
 func WriteHTTPWithContext(ctx context.Context, w http.ResponseWriter, err error) {
 	status := HTTPStatus(err)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
 	if err == nil {
+		w.WriteHeader(status)
 		return
 	}
-	msg := "internal error"
-	code := CodeInternalError
-	if e, ok := err.(*Error); ok {
-		msg = e.Message
-		code = e.Code
+	locale := LocaleFromContext(ctx)
+	resp := Response{
+		Code:    CodeInternalError,
+		Message: "internal error",
+		TraceID: applog.TraceIDFromContext(ctx),
 	}
-	traceID := applog.TraceIDFromContext(ctx)
-	report(code)
-	_ = json.NewEncoder(w).Encode(Response{
-		Code:    code,
-		Message: msg,
-		TraceID: traceID,
-	})
+	if e, ok := asError(err); ok {
+		resp.Code = e.Code
+		resp.Message = e.Localize(locale)
+		if !e.Details.IsZero() {
+			resp.Details = &e.Details
+		}
+	} else if msg, ok := lookupMessage(locale, CodeInternalError); ok {
+		resp.Message = msg
+	}
+	if locale != "" {
+		w.Header().Set("Content-Language", locale)
+	}
+	w.WriteHeader(status)
+	report(resp.Code)
+	_ = json.NewEncoder(w).Encode(resp)
 }
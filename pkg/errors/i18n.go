@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	catalogMu sync.RWMutex
+	catalogs  = make(map[string]map[int]string) // locale -> code -> message
+	// defaultLocale 是 Error.Message 与登记表默认消息所用的语言，协商时视为始终支持
+	defaultLocale = "en"
+)
+
+// SetDefaultLocale 设置 Error.Message 所用的语言（默认 "en"）
+func SetDefaultLocale(locale string) {
+	catalogMu.Lock()
+	defaultLocale = normalizeLocale(locale)
+	catalogMu.Unlock()
+}
+
+func init() {
+	RegisterMessages("zh", map[int]string{
+		CodeOK:              "成功",
+		CodeBadRequest:      "请求参数错误",
+		CodeUnauthorized:    "未授权",
+		CodeNotFound:        "资源不存在",
+		CodeTooManyRequests: "请求过于频繁，请稍后重试",
+		CodeInternalError:   "服务内部错误",
+	})
+}
+
+// RegisterMessages 登记某个语言（如 "zh"、"en-US"）下各错误码的消息，消息中的 {name} 由 WithParam 的参数替换；
+// 同一语言多次登记时合并，后登记的覆盖先登记的
+func RegisterMessages(locale string, messages map[int]string) {
+	locale = normalizeLocale(locale)
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	c, ok := catalogs[locale]
+	if !ok {
+		c = make(map[int]string, len(messages))
+		catalogs[locale] = c
+	}
+	for code, msg := range messages {
+		c[code] = msg
+	}
+}
+
+// WithParam 返回附加了模板参数的副本，用于替换消息中的 {name}
+func (e *Error) WithParam(name string, value any) *Error {
+	c := e.clone()
+	c.Params = make(map[string]any, len(e.Params)+1)
+	for k, v := range e.Params {
+		c.Params[k] = v
+	}
+	c.Params[name] = value
+	return c
+}
+
+// Localize 按 locale 渲染错误消息：消息取自登记表默认消息（New/Wrap 时 msg 为空）时，
+// 优先使用该语言（或其基础语言，如 zh-CN -> zh）目录中错误码对应的消息；显式传入的消息原样保留。
+// 两者都会替换 {name} 参数
+func (e *Error) Localize(locale string) string {
+	msg := e.Message
+	if e.defaultMsg || msg == "" {
+		if m, ok := lookupMessage(locale, e.Code); ok {
+			msg = m
+		}
+	}
+	return renderParams(msg, e.Params)
+}
+
+func lookupMessage(locale string, code int) (string, bool) {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return "", false
+	}
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	for _, l := range []string{locale, baseLanguage(locale)} {
+		if msg, ok := catalogs[l][code]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+func renderParams(msg string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+type localeKey struct{}
+
+// WithLocale 将协商得到的语言保存到 ctx，WriteHTTPWithContext 据此渲染消息
+func WithLocale(ctx context.Context, locale string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, localeKey{}, normalizeLocale(locale))
+}
+
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// NegotiateLocale 按 Accept-Language（含 q 权重）在已登记消息目录的语言中选择最合适的一个，
+// 支持基础语言回退（zh-TW -> zh）；没有匹配时返回空字符串
+func NegotiateLocale(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalizeLocale(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	supported := map[string]bool{defaultLocale: true}
+	for locale := range catalogs {
+		supported[locale] = true
+	}
+	locales := make([]string, 0, len(supported))
+	for locale := range supported {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, c := range candidates {
+		if supported[c.tag] {
+			return c.tag
+		}
+		if base := baseLanguage(c.tag); supported[base] {
+			return base
+		}
+		// 请求 "zh" 而目录只有 "zh-cn" 时同样匹配
+		for _, locale := range locales {
+			if baseLanguage(locale) == c.tag {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
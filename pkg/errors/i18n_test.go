@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+	applog "mini-jupiter/pkg/log"
+)
+
+func init() {
+	RegisterMessages("zh", map[int]string{codeUserBanned: "用户 {id} 已被封禁"})
+	RegisterMessages("zh-TW", map[int]string{codeUserBanned: "使用者 {id} 已被停權"})
+}
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		locale string
+		want   string
+	}{
+		{"registry default", New(CodeNotFound, ""), "zh", "资源不存在"},
+		{"base language fallback", New(CodeNotFound, ""), "zh-CN", "资源不存在"},
+		{"exact locale first", New(codeUserBanned, "").WithParam("id", 7), "zh-tw", "使用者 7 已被停權"},
+		{"params in catalog message", New(codeUserBanned, "").WithParam("id", 7), "zh", "用户 7 已被封禁"},
+		{"no locale", New(CodeNotFound, ""), "", "not found"},
+		{"no catalog entry", New(codeBusy, ""), "zh", "busy"},
+		{"wrapped default", Wrap(CodeInternalError, "", io.EOF), "zh", "服务内部错误"},
+		{"explicit message kept", New(CodeNotFound, "user 7 has no orders"), "zh", "user 7 has no orders"},
+		{"explicit message with params", New(CodeBadRequest, "bad {field}").WithParam("field", "id"), "zh", "bad id"},
+		{"explicit message kept after copy", New(CodeNotFound, "gone").WithDetail("id", "7"), "zh", "gone"},
+		{"literal without message", &Error{Code: CodeNotFound}, "zh", "资源不存在"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.Equal(t, tt.err.Localize(tt.locale), tt.want)
+		})
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{"zh-CN,zh;q=0.9,en;q=0.8", "zh"},
+		{"zh-TW", "zh-tw"},
+		{"en;q=0.5, zh;q=0.8", "zh"},
+		{"fr, en-US;q=0.7", "en"},
+		{"fr, *;q=0.5", ""},
+		{"zh;q=0, en;q=0.1", "en"},
+		{"zh;q=bad, en", "en"},
+	}
+	for _, tt := range tests {
+		if got := NegotiateLocale(tt.header); got != tt.want {
+			t.Errorf("NegotiateLocale(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestWriteHTTPWithContext(t *testing.T) {
+	ctx := WithLocale(applog.WithTraceID(context.Background(), "abc"), "zh-CN")
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		status   int
+		want     Response
+		language string
+	}{
+		{
+			name:   "localized default message",
+			ctx:    ctx,
+			err:    New(codeUserBanned, "").WithParam("id", 7).WithDetail("until", "tomorrow"),
+			status: http.StatusForbidden,
+			want: Response{Code: codeUserBanned, Message: "用户 7 已被封禁", TraceID: "abc",
+				Details: &Details{Metadata: map[string]string{"until": "tomorrow"}}},
+			language: "zh-cn",
+		},
+		{
+			name:     "explicit message",
+			ctx:      ctx,
+			err:      New(CodeBadRequest, "method not allowed"),
+			status:   http.StatusBadRequest,
+			want:     Response{Code: CodeBadRequest, Message: "method not allowed", TraceID: "abc"},
+			language: "zh-cn",
+		},
+		{
+			name:     "plain error",
+			ctx:      ctx,
+			err:      io.EOF,
+			status:   http.StatusInternalServerError,
+			want:     Response{Code: CodeInternalError, Message: "服务内部错误", TraceID: "abc"},
+			language: "zh-cn",
+		},
+		{
+			name:   "no locale",
+			ctx:    context.Background(),
+			err:    New(CodeNotFound, ""),
+			status: http.StatusNotFound,
+			want:   Response{Code: CodeNotFound, Message: "not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteHTTPWithContext(tt.ctx, rec, tt.err)
+			testutil.Equal(t, rec.Code, tt.status)
+			testutil.Equal(t, rec.Header().Get("Content-Language"), tt.language)
+			var got Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshal %q: %v", rec.Body, err)
+			}
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(tt.want)
+			testutil.Equal(t, string(gotJSON), string(wantJSON))
+		})
+	}
+}
@@ -6,14 +6,18 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
 )
 
 var ErrRejected = errors.New("request rejected")
 
 type RouteConfig struct {
-	MaxConcurrent int `mapstructure:"max_concurrent" yaml:"max_concurrent"`
-	MaxQueue      int `mapstructure:"max_queue" yaml:"max_queue"`
-	WaitTimeoutMs int `mapstructure:"wait_timeout_ms" yaml:"wait_timeout_ms"`
+	MaxConcurrent int `mapstructure:"max_concurrent" yaml:"max_concurrent" default:"1" validate:"min=1"`
+	MaxQueue      int `mapstructure:"max_queue" yaml:"max_queue" validate:"min=0"`
+	WaitTimeoutMs int `mapstructure:"wait_timeout_ms" yaml:"wait_timeout_ms" default:"50" validate:"min=0"`
 }
 
 type Config struct {
@@ -28,6 +32,8 @@ type Limiter struct {
 	waitTimeout time.Duration
 }
 
+// NewLimiter 创建路由限流器；配置的默认值由 RouteConfig 的标签提供，
+// 非法输入按标签默认值处理：maxConcurrent <= 0 为 1，maxQueue < 0 为 0，waitTimeout <= 0 为 50ms
 func NewLimiter(maxConcurrent, maxQueue int, waitTimeout time.Duration) *Limiter {
 	if maxConcurrent <= 0 {
 		maxConcurrent = 1
@@ -82,10 +88,22 @@ type Manager struct {
 
 func NewManager(cfg Config) *Manager {
 	m := &Manager{limiters: make(map[string]*Limiter)}
+	m.Update(cfg)
+	return m
+}
+
+// Update 按新配置重建路由限流器，已持有旧限流器的请求在旧信号量上释放
+func (m *Manager) Update(cfg Config) {
+	limiters := make(map[string]*Limiter, len(cfg.Routes))
 	for route, rc := range cfg.Routes {
-		m.limiters[route] = NewLimiter(rc.MaxConcurrent, rc.MaxQueue, time.Duration(rc.WaitTimeoutMs)*time.Millisecond)
+		limiters[route] = NewLimiter(rc.MaxConcurrent, rc.MaxQueue, time.Duration(rc.WaitTimeoutMs)*time.Millisecond)
 	}
-	return m
+	m.mu.Lock()
+	m.limiters = limiters
+	m.mu.Unlock()
+	applog.Named("isolation").Debug("isolation routes updated",
+		zap.Int("routes", len(limiters)),
+	)
 }
 
 func (m *Manager) Limiter(path string) *Limiter {
@@ -0,0 +1,94 @@
+package isolation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireConcurrency(t *testing.T) {
+	l := NewLimiter(1, 0, time.Millisecond)
+	release, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrRejected) {
+		t.Fatalf("second Acquire error = %v, want ErrRejected", err)
+	}
+	release()
+	if _, err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+}
+
+func TestAcquireQueue(t *testing.T) {
+	l := NewLimiter(1, 1, time.Second)
+	release, _ := l.Acquire(context.Background())
+
+	done := make(chan error, 1)
+	go func() {
+		r, err := l.Acquire(context.Background())
+		if err == nil {
+			r()
+		}
+		done <- err
+	}()
+	// 等待第二个请求进入队列，队列已满时第三个请求直接被拒绝
+	deadline := time.Now().Add(time.Second)
+	for l.queued.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrRejected) {
+		t.Fatalf("Acquire with full queue error = %v, want ErrRejected", err)
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("queued Acquire: %v", err)
+	}
+}
+
+func TestAcquireTimeoutAndCancel(t *testing.T) {
+	l := NewLimiter(1, 1, 10*time.Millisecond)
+	release, _ := l.Acquire(context.Background())
+	defer release()
+
+	if _, err := l.Acquire(context.Background()); !errors.Is(err, ErrRejected) {
+		t.Fatalf("Acquire error = %v, want ErrRejected after wait timeout", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l = NewLimiter(1, 1, time.Second)
+	hold, _ := l.Acquire(context.Background())
+	defer hold()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire error = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewLimiterNonPositiveInputs(t *testing.T) {
+	l := NewLimiter(-1, -1, 0)
+	if cap(l.sem) != 1 || l.maxQueue != 0 || l.waitTimeout != 50*time.Millisecond {
+		t.Fatalf("NewLimiter(-1, -1, 0) = cap %d, queue %d, timeout %v", cap(l.sem), l.maxQueue, l.waitTimeout)
+	}
+	release, err := NewLimiter(0, 0, 0).Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("NewLimiter(0, 0, 0) rejected the first request: %v", err)
+	}
+	release()
+}
+
+func TestManagerUpdate(t *testing.T) {
+	m := NewManager(Config{Routes: map[string]RouteConfig{"/a": {MaxConcurrent: 1}}})
+	if m.Limiter("/a") == nil || m.Limiter("/b") != nil {
+		t.Fatal("unexpected limiters after NewManager")
+	}
+	m.Update(Config{Routes: map[string]RouteConfig{"/b": {MaxConcurrent: 2}}})
+	if m.Limiter("/a") != nil || m.Limiter("/b") == nil {
+		t.Fatal("unexpected limiters after Update")
+	}
+	var nilMgr *Manager
+	if nilMgr.Limiter("/a") != nil {
+		t.Fatal("nil Manager returned a limiter")
+	}
+}
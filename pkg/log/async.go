@@ -0,0 +1,188 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	OverflowBlock      = "block"
+	OverflowDropNewest = "drop_newest"
+	OverflowDropOldest = "drop_oldest"
+)
+
+// AsyncConfig 开启后日志经由有界队列异步写出，写日志的 goroutine 不再等待磁盘或 stdout；
+// 队列满时按 Overflow 处理：block 等待、drop_newest 丢弃当前条目、drop_oldest 丢弃最早的条目
+type AsyncConfig struct {
+	Enabled       bool          `mapstructure:"enabled" yaml:"enabled"`
+	BufferSize    int           `mapstructure:"buffer_size" yaml:"buffer_size" default:"4096" validate:"min=0"`
+	FlushInterval time.Duration `mapstructure:"flush_interval" yaml:"flush_interval" default:"1s"`
+	Overflow      string        `mapstructure:"overflow" yaml:"overflow" default:"block" validate:"omitempty,oneof=block drop_newest drop_oldest"`
+}
+
+var asyncOut atomic.Pointer[asyncWriter]
+
+// asyncBatchSize 是后台 goroutine 合并写出的上限，超过时先写出已积累的条目
+const asyncBatchSize = 256 * 1024
+
+// asyncWriter 是异步 WriteSyncer：Write 只拷贝并入队，后台 goroutine 按条目边界合并后写出
+// （一次 Write 只包含完整的条目，rotate sink 不会把一行日志切到两个文件），
+// 按 FlushInterval 定期刷新，Sync 等待队列清空并同步底层输出
+type asyncWriter struct {
+	out      zapcore.WriteSyncer
+	overflow string
+	interval time.Duration
+
+	queue   chan []byte
+	flushes chan chan error
+	done    chan struct{}
+	exited  chan struct{}
+	once    sync.Once
+}
+
+func newAsyncWriter(out zapcore.WriteSyncer, cfg AsyncConfig) *asyncWriter {
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = 4096
+	}
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	w := &asyncWriter{
+		out:      out,
+		overflow: cfg.Overflow,
+		interval: interval,
+		queue:    make(chan []byte, size),
+		flushes:  make(chan chan error),
+		done:     make(chan struct{}),
+		exited:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.done:
+		// 已停止：直接同步写出，避免丢失 Stop 之后的日志
+		return w.out.Write(p)
+	default:
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	switch w.overflow {
+	case OverflowDropNewest:
+		select {
+		case w.queue <- b:
+		default:
+			reportOverflow()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- b:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				reportOverflow()
+			default:
+			}
+		}
+	default:
+		select {
+		case w.queue <- b:
+		case <-w.done:
+			return w.out.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// Sync 等待已入队的条目全部写出并同步底层输出
+func (w *asyncWriter) Sync() error {
+	req := make(chan error, 1)
+	select {
+	case w.flushes <- req:
+		return <-req
+	case <-w.exited:
+		return w.out.Sync()
+	}
+}
+
+// Stop 写出剩余条目后停止后台 goroutine，可重复调用
+func (w *asyncWriter) Stop() {
+	w.once.Do(func() {
+		close(w.done)
+	})
+	<-w.exited
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.exited)
+	batch := make([]byte, 0, asyncBatchSize)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := w.out.Write(batch)
+		batch = batch[:0]
+		return err
+	}
+	add := func(b []byte) {
+		if len(batch)+len(b) > asyncBatchSize {
+			_ = flush()
+		}
+		if len(b) >= asyncBatchSize {
+			_, _ = w.out.Write(b)
+			return
+		}
+		batch = append(batch, b...)
+	}
+	drain := func() {
+		for {
+			select {
+			case b := <-w.queue:
+				add(b)
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case b := <-w.queue:
+			add(b)
+		case <-ticker.C:
+			_ = flush()
+		case req := <-w.flushes:
+			drain()
+			err := flush()
+			if serr := w.out.Sync(); err == nil {
+				err = serr
+			}
+			req <- err
+		case <-w.done:
+			drain()
+			_ = flush()
+			_ = w.out.Sync()
+			return
+		}
+	}
+}
+
+// reportOverflow 上报队列溢出丢弃的条目，此时条目已编码，level 标签为空
+func reportOverflow() {
+	if r := dropReporter.Load(); r != nil {
+		(*r)(DropReasonOverflow, "")
+	}
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+)
+
+// writesSink 记录每一次 Write 的内容
+type writesSink struct {
+	mu     sync.Mutex
+	writes [][]byte
+}
+
+func (s *writesSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (s *writesSink) Sync() error {
+	return nil
+}
+
+func TestAsyncWriterWritesWholeEntries(t *testing.T) {
+	sink := &writesSink{}
+	w := newAsyncWriter(sink, AsyncConfig{BufferSize: 16, FlushInterval: time.Millisecond})
+	defer w.Stop()
+
+	const n = 2000
+	for i := 0; i < n; i++ {
+		// 条目长度不一，保证会跨越合并上限
+		entry := fmt.Sprintf("{\"i\":%d,\"pad\":%q}\n", i, strings.Repeat("x", i%700))
+		if _, err := w.Write([]byte(entry)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	big := strings.Repeat("y", asyncBatchSize+10) + "\n"
+	_, _ = w.Write([]byte(big))
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.writes) < 2 {
+		t.Fatalf("got %d writes, want entries split into several batches", len(sink.writes))
+	}
+	lines := 0
+	for _, p := range sink.writes {
+		if len(p) > asyncBatchSize && string(p) != big {
+			t.Errorf("batch of %d bytes exceeds the limit", len(p))
+		}
+		if !bytes.HasSuffix(p, []byte("\n")) {
+			t.Fatalf("write does not end on an entry boundary: %q", p[max(0, len(p)-20):])
+		}
+		lines += bytes.Count(p, []byte("\n"))
+	}
+	testutil.Equal(t, lines, n+1)
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	tests := []struct {
+		overflow string
+		want     []string
+	}{
+		{OverflowDropNewest, []string{"a", "b"}},
+		{OverflowDropOldest, []string{"b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			var dropped int
+			SetDropReporter(func(reason, _ string) {
+				if reason == DropReasonOverflow {
+					dropped++
+				}
+			})
+			t.Cleanup(func() { SetDropReporter(nil) })
+
+			// 不启动后台 goroutine，队列保持满的状态
+			w := &asyncWriter{overflow: tt.overflow, queue: make(chan []byte, 2), done: make(chan struct{})}
+			for _, s := range []string{"a", "b", "c"} {
+				if n, err := w.Write([]byte(s)); n != 1 || err != nil {
+					t.Fatalf("Write = %d, %v", n, err)
+				}
+			}
+			close(w.queue)
+			var got []string
+			for b := range w.queue {
+				got = append(got, string(b))
+			}
+			testutil.Equal(t, got, tt.want)
+			testutil.Equal(t, dropped, 1)
+		})
+	}
+}
+
+func TestAsyncWriterStop(t *testing.T) {
+	sink := &writesSink{}
+	w := newAsyncWriter(sink, AsyncConfig{FlushInterval: time.Hour})
+	_, _ = w.Write([]byte("queued\n"))
+	w.Stop()
+	w.Stop()
+	// 停止后同步写出
+	_, _ = w.Write([]byte("after stop\n"))
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	testutil.Equal(t, string(bytes.Join(sink.writes, nil)), "queued\nafter stop\n")
+}
+
+func TestAsyncWithRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	initForTest(t, Config{
+		Encoding:    "json",
+		OutputPaths: []string{path},
+		Rotation:    RotationConfig{Enabled: true, MaxSizeMB: 1},
+		Sampling:    SamplingConfig{Disabled: true},
+		// 刷新间隔足够长，写出只由合并上限触发
+		Async: AsyncConfig{Enabled: true, BufferSize: 64, FlushInterval: time.Hour},
+	})
+	pad := strings.Repeat("x", 900)
+	const n = 4000
+	for i := 0; i < n; i++ {
+		zap.L().Info("entry", zap.Int("i", i), zap.String("pad", pad))
+	}
+	Sync()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) < 2 {
+		t.Fatalf("got %d files, want rotated backups", len(entries))
+	}
+	total := 0
+	for _, e := range entries {
+		data := readFile(t, filepath.Join(dir, e.Name()))
+		for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("%s: broken line %.60q: %v", e.Name(), line, err)
+			}
+			total++
+		}
+	}
+	testutil.Equal(t, total, n)
+}
@@ -2,17 +2,56 @@ package log
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
 
 type traceIDKey struct{}
 
+type fieldsKey struct{}
+
+// ctxFields 保存 ctx 上累积的字段以及据此构建的子 logger
+type ctxFields struct {
+	fields  []zap.Field
+	cached  cachedLoggerRef
+	named   sync.Map // map[string]*cachedLoggerRef
+	limited sync.Map // map[*siteLimiter]*cachedLoggerRef
+}
+
+func (f *ctxFields) logger() *zap.Logger {
+	return f.cached.get(func(base *zap.Logger) *zap.Logger {
+		return base.With(f.fields...)
+	})
+}
+
+// cachedLoggerRef 缓存由全局 logger 派生的子 logger，只在首次使用或全局 logger 被替换（Init）后重建
+type cachedLoggerRef struct {
+	p atomic.Pointer[cachedLogger]
+}
+
+type cachedLogger struct {
+	base   *zap.Logger
+	logger *zap.Logger
+}
+
+func (r *cachedLoggerRef) get(build func(base *zap.Logger) *zap.Logger) *zap.Logger {
+	base := zap.L()
+	if c := r.p.Load(); c != nil && c.base == base {
+		return c.logger
+	}
+	logger := build(base)
+	r.p.Store(&cachedLogger{base: base, logger: logger})
+	return logger
+}
+
 func WithTraceID(ctx context.Context, id string) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return context.WithValue(ctx, traceIDKey{}, id)
+	ctx = context.WithValue(ctx, traceIDKey{}, id)
+	return WithFields(ctx, zap.String("trace_id", id))
 }
 
 func TraceIDFromContext(ctx context.Context) string {
@@ -27,12 +66,42 @@ func TraceIDFromContext(ctx context.Context) string {
 	return ""
 }
 
+// WithFields 将字段附加到 ctx，之后 L(ctx)/S(ctx) 输出的每条日志都会带上这些字段（含已有字段）
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+	parent := FieldsFromContext(ctx)
+	all := make([]zap.Field, 0, len(parent)+len(fields))
+	all = append(all, parent...)
+	all = append(all, fields...)
+	return context.WithValue(ctx, fieldsKey{}, &ctxFields{fields: all})
+}
+
+// FieldsFromContext 返回 ctx 上通过 WithFields/WithTraceID 附加的字段，调用方不应修改返回值
+func FieldsFromContext(ctx context.Context) []zap.Field {
+	if f := fieldsFrom(ctx); f != nil {
+		return f.fields
+	}
+	return nil
+}
+
+func fieldsFrom(ctx context.Context) *ctxFields {
+	if ctx == nil {
+		return nil
+	}
+	f, _ := ctx.Value(fieldsKey{}).(*ctxFields)
+	return f
+}
+
 func L(ctx context.Context) *zap.Logger {
-	traceID := TraceIDFromContext(ctx)
-	if traceID == "" {
-		return zap.L()
+	if f := fieldsFrom(ctx); f != nil {
+		return f.logger()
 	}
-	return zap.L().With(zap.String("trace_id", traceID))
+	return zap.L()
 }
 
 func S(ctx context.Context) *zap.SugaredLogger {
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+)
+
+func TestWithFields(t *testing.T) {
+	logs := observe(t)
+
+	ctx := WithTraceID(context.Background(), "abc")
+	ctx = WithFields(ctx, zap.String("user", "u1"))
+	child := WithFields(ctx, zap.String("route", "/ping"))
+	testutil.Equal(t, TraceIDFromContext(child), "abc")
+	testutil.Equal(t, len(FieldsFromContext(ctx)), 2)
+	testutil.Equal(t, len(FieldsFromContext(child)), 3)
+	testutil.Equal(t, FieldsFromContext(context.Background()), []zap.Field(nil))
+	if WithFields(ctx) != ctx {
+		t.Error("WithFields without fields returned a new ctx")
+	}
+
+	L(child).Info("request", zap.Int("status", 200))
+	S(ctx).Infow("sugared")
+	NamedL(child, "pool").Info("task")
+	L(context.Background()).Info("plain")
+
+	all := logs.All()
+	testutil.Equal(t, all[0].ContextMap(), map[string]any{"trace_id": "abc", "user": "u1", "route": "/ping", "status": int64(200)})
+	testutil.Equal(t, all[1].ContextMap(), map[string]any{"trace_id": "abc", "user": "u1"})
+	testutil.Equal(t, all[2].LoggerName, "pool")
+	testutil.Equal(t, all[2].ContextMap()["route"], any("/ping"))
+	testutil.Equal(t, len(all[3].Context), 0)
+}
+
+func TestContextLoggerCached(t *testing.T) {
+	observe(t)
+	ctx := WithFields(context.Background(), zap.String("user", "u1"))
+	first := L(ctx)
+	if L(ctx) != first {
+		t.Error("L(ctx) rebuilt the child logger")
+	}
+	// 全局 logger 替换（Init）后重建
+	logs := observe(t)
+	if L(ctx) == first {
+		t.Error("L(ctx) kept the child of the replaced global logger")
+	}
+	L(ctx).Info("after replace")
+	testutil.Equal(t, logs.FilterField(zap.String("user", "u1")).Len(), 1)
+}
+
+func TestNamedLCached(t *testing.T) {
+	observe(t)
+	ctx := WithFields(context.Background(), zap.String("user", "u1"))
+	first := NamedL(ctx, "pool")
+	if NamedL(ctx, "pool") != first {
+		t.Error("NamedL rebuilt the module logger for the same ctx")
+	}
+	if NamedL(ctx, "api") == first {
+		t.Error("NamedL shared a logger across module names")
+	}
+	testutil.Equal(t, testing.AllocsPerRun(100, func() { NamedL(ctx, "pool") }), float64(0))
+
+	logs := observe(t)
+	if NamedL(ctx, "pool") == first {
+		t.Error("NamedL kept the logger of the replaced global")
+	}
+	NamedL(ctx, "pool").Info("after replace")
+	testutil.Equal(t, logs.FilterField(zap.String("user", "u1")).Len(), 1)
+}
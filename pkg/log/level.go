@@ -0,0 +1,208 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// levelRegistry 保存全局级别与按 logger 名称的覆盖级别，Init 重建 logger 时保留
+type levelRegistry struct {
+	global    zap.AtomicLevel
+	mu        sync.RWMutex
+	overrides map[string]zapcore.Level
+	min       zap.AtomicLevel
+}
+
+var levels = &levelRegistry{
+	global:    zap.NewAtomicLevelAt(zap.InfoLevel),
+	overrides: make(map[string]zapcore.Level),
+	min:       zap.NewAtomicLevelAt(zap.InfoLevel),
+}
+
+// levelFor 按 logger 名称逐级向上查找覆盖级别（"pool.worker" -> "pool"），未配置时使用全局级别
+func (r *levelRegistry) levelFor(name string) zapcore.Level {
+	if name != "" {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if len(r.overrides) > 0 {
+			for n := name; n != ""; {
+				if lvl, ok := r.overrides[n]; ok {
+					return lvl
+				}
+				idx := strings.LastIndex(n, ".")
+				if idx < 0 {
+					break
+				}
+				n = n[:idx]
+			}
+		}
+	}
+	return r.global.Level()
+}
+
+func (r *levelRegistry) set(name string, lvl zapcore.Level) {
+	if name == "" {
+		r.global.SetLevel(lvl)
+	} else {
+		r.mu.Lock()
+		r.overrides[name] = lvl
+		r.mu.Unlock()
+	}
+	r.recompute()
+}
+
+func (r *levelRegistry) reset(name string) {
+	r.mu.Lock()
+	delete(r.overrides, name)
+	r.mu.Unlock()
+	r.recompute()
+}
+
+func (r *levelRegistry) recompute() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	min := r.global.Level()
+	for _, lvl := range r.overrides {
+		if lvl < min {
+			min = lvl
+		}
+	}
+	r.min.SetLevel(min)
+}
+
+// levelCore 按 entry 的 logger 名称决定是否输出，内层 core 应以最低级别构建
+type levelCore struct {
+	zapcore.Core
+}
+
+func (c *levelCore) Enabled(lvl zapcore.Level) bool {
+	return levels.min.Enabled(lvl)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields)}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < levels.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func parseLevel(s string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	if err := lvl.Set(strings.ToLower(s)); err != nil {
+		return lvl, err
+	}
+	return lvl, nil
+}
+
+// SetLevel 在运行时调整全局日志级别
+func SetLevel(level string) error {
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levels.set("", lvl)
+	return nil
+}
+
+func GetLevel() zapcore.Level {
+	return levels.global.Level()
+}
+
+// SetLoggerLevel 为某个命名 logger（logger.Named(name)）及其子 logger 单独设置级别
+func SetLoggerLevel(name, level string) error {
+	if name == "" {
+		return SetLevel(level)
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+	levels.set(name, lvl)
+	return nil
+}
+
+// ResetLoggerLevel 移除命名 logger 的级别覆盖，恢复跟随全局级别
+func ResetLoggerLevel(name string) {
+	levels.reset(name)
+}
+
+// LoggerLevels 返回当前所有命名 logger 的覆盖级别
+func LoggerLevels() map[string]string {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	out := make(map[string]string, len(levels.overrides))
+	for name, lvl := range levels.overrides {
+		out[name] = lvl.String()
+	}
+	return out
+}
+
+type levelPayload struct {
+	Level   string            `json:"level"`
+	Logger  string            `json:"logger,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// LevelHandler 提供日志级别管理接口：
+//
+//	GET                                   -> {"level":"info","loggers":{"pool":"debug"}}
+//	PUT {"level":"debug"}                 -> 调整全局级别
+//	PUT {"logger":"pool","level":"debug"} -> 调整命名 logger 级别，level 为空表示移除覆盖
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var req levelPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+				return
+			}
+			if req.Logger == "" {
+				req.Logger = r.URL.Query().Get("logger")
+			}
+			var err error
+			switch {
+			case req.Logger == "" && req.Level == "":
+				err = errors.New("level is required")
+			case req.Logger != "" && req.Level == "":
+				ResetLoggerLevel(req.Logger)
+			default:
+				err = SetLoggerLevel(req.Logger, req.Level)
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			zap.L().Info("log level changed",
+				zap.String("target_logger", req.Logger),
+				zap.String("new_level", req.Level),
+			)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levelPayload{
+			Level:   GetLevel().String(),
+			Loggers: LoggerLevels(),
+		})
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
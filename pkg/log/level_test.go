@@ -0,0 +1,141 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeLevels 以 levelCore 包装 observer 作为全局 logger，测试结束时恢复级别配置
+func observeLevels(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	restore := zap.ReplaceGlobals(zap.New(&levelCore{Core: core}))
+	t.Cleanup(func() {
+		restore()
+		_ = SetModuleLevels(nil)
+		for name := range LoggerLevels() {
+			ResetLoggerLevel(name)
+		}
+		levels.set("", zapcore.InfoLevel)
+	})
+	return logs
+}
+
+func TestNamedLoggerLevels(t *testing.T) {
+	logs := observeLevels(t)
+	if err := SetLoggerLevel("pool", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLoggerLevel("isolation", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if Named("pool") != Named("pool") {
+		t.Error("Named rebuilt the module logger")
+	}
+
+	Named("pool").Debug("pool debug")
+	Named("pool").Named("worker").Debug("worker debug")
+	Named("isolation").Warn("isolation warn")
+	Named("http").Debug("http debug")
+	zap.L().Debug("root debug")
+	zap.L().Info("root info")
+
+	var got []string
+	for _, e := range logs.TakeAll() {
+		got = append(got, e.Message)
+	}
+	testutil.Equal(t, got, []string{"pool debug", "worker debug", "root info"})
+	testutil.Equal(t, LoggerLevels(), map[string]string{"pool": "debug", "isolation": "error"})
+
+	ResetLoggerLevel("pool")
+	Named("pool").Debug("after reset")
+	testutil.Equal(t, logs.Len(), 0)
+	if err := SetLoggerLevel("pool", "loud"); err == nil {
+		t.Error("SetLoggerLevel accepted an invalid level")
+	}
+}
+
+func TestSetModuleLevels(t *testing.T) {
+	observeLevels(t)
+	if err := SetLoggerLevel("manual", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetModuleLevels(map[string]string{"pool": "debug", "isolation": "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	// 配置中移除的模块恢复跟随全局级别，手动设置的覆盖保留
+	if err := SetModuleLevels(map[string]string{"pool": "error"}); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Equal(t, LoggerLevels(), map[string]string{"pool": "error", "manual": "warn"})
+
+	for _, bad := range []map[string]string{{"": "debug"}, {"pool": "loud"}} {
+		if err := SetModuleLevels(bad); err == nil {
+			t.Errorf("SetModuleLevels(%v) succeeded", bad)
+		}
+	}
+	testutil.Equal(t, LoggerLevels()["pool"], "error")
+}
+
+func TestLevelHandler(t *testing.T) {
+	observeLevels(t)
+	h := LevelHandler()
+	do := func(method, body string) (int, levelPayload) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/admin/log/level", strings.NewReader(body)))
+		var out levelPayload
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatalf("unmarshal %q: %v", rec.Body, err)
+			}
+		}
+		return rec.Code, out
+	}
+
+	code, out := do(http.MethodPut, `{"level":"warn"}`)
+	testutil.Equal(t, code, http.StatusOK)
+	testutil.Equal(t, out.Level, "warn")
+
+	code, out = do(http.MethodPut, `{"logger":"pool","level":"debug"}`)
+	testutil.Equal(t, code, http.StatusOK)
+	testutil.Equal(t, out.Loggers, map[string]string{"pool": "debug"})
+
+	code, out = do(http.MethodPut, `{"logger":"pool"}`)
+	testutil.Equal(t, code, http.StatusOK)
+	testutil.Equal(t, out.Loggers, map[string]string(nil))
+
+	for _, body := range []string{`{}`, `{"level":"loud"}`, `not json`} {
+		code, _ = do(http.MethodPut, body)
+		testutil.Equal(t, code, http.StatusBadRequest)
+	}
+	code, _ = do(http.MethodDelete, "")
+	testutil.Equal(t, code, http.StatusMethodNotAllowed)
+	code, out = do(http.MethodGet, "")
+	testutil.Equal(t, code, http.StatusOK)
+	testutil.Equal(t, out.Level, "warn")
+}
+
+func TestLevelHandlerLogFields(t *testing.T) {
+	logs := observeLevels(t)
+	rec := httptest.NewRecorder()
+	LevelHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log/level",
+		strings.NewReader(`{"logger":"pool","level":"debug"}`)))
+	testutil.Equal(t, rec.Code, http.StatusOK)
+
+	// 字段名避开编码器保留的 logger、level 键
+	entries := logs.FilterMessage("log level changed").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	testutil.Equal(t, entries[0].ContextMap(), map[string]any{"target_logger": "pool", "new_level": "debug"})
+}
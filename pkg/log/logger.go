@@ -1,18 +1,54 @@
 package log
 
 import (
+	"fmt"
+	"log/slog"
 	"strings"
+	"sync"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Config struct {
-	Level           string   `mapstructure:"level" yaml:"level"`
-	Encoding        string   `mapstructure:"encoding" yaml:"encoding"`
-	OutputPaths     []string `mapstructure:"output_paths" yaml:"output_paths"`
+	Level            string   `mapstructure:"level" yaml:"level" default:"info"`
+	Encoding         string   `mapstructure:"encoding" yaml:"encoding" default:"console" validate:"omitempty,oneof=console json"`
+	OutputPaths      []string `mapstructure:"output_paths" yaml:"output_paths"`
 	ErrorOutputPaths []string `mapstructure:"error_output_paths" yaml:"error_output_paths"`
+	// OutputPaths/ErrorOutputPaths 中的普通文件路径统一由 rotate sink 写入（支持 Reopen），
+	// Rotation 未开启时只是不切割
+	Rotation RotationConfig `mapstructure:"rotation" yaml:"rotation"`
+	Sampling SamplingConfig `mapstructure:"sampling" yaml:"sampling"`
+	Redact   RedactConfig   `mapstructure:"redact" yaml:"redact"`
+	Ring     RingConfig     `mapstructure:"ring" yaml:"ring"`
+	// Modules 按模块（logger 名称）单独设置级别，如 {pool: debug}
+	Modules map[string]string `mapstructure:"modules" yaml:"modules"`
+	Async   AsyncConfig       `mapstructure:"async" yaml:"async"`
 }
 
+func (c Config) Validate() error {
+	if c.Level != "" {
+		var level zapcore.Level
+		if err := level.Set(strings.ToLower(c.Level)); err != nil {
+			return fmt.Errorf("level: %w", err)
+		}
+	}
+	for name, level := range c.Modules {
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("modules.%s: %w", name, err)
+		}
+	}
+	if _, err := newRedactor(c.Redact); err != nil {
+		return fmt.Errorf("redact: %w", err)
+	}
+	return nil
+}
+
+var (
+	initMu       sync.Mutex
+	closeOutputs func() // 关闭当前全局 logger 的输出，下次 Init 替换后调用
+)
+
 func Init(cfg Config) error {
 	zcfg := zap.NewProductionConfig()
 	zcfg.Encoding = "console"
@@ -26,7 +62,16 @@ func Init(cfg Config) error {
 			return err
 		}
 	}
-	zcfg.Level = zap.NewAtomicLevelAt(level)
+	// 级别过滤交给 levelCore，内层 core 放开到最低级别，运行时可通过 SetLevel/SetLoggerLevel 调整
+	levels.set("", level)
+	if err := SetModuleLevels(cfg.Modules); err != nil {
+		return err
+	}
+
+	red, err := newRedactor(cfg.Redact)
+	if err != nil {
+		return err
+	}
 
 	if len(cfg.OutputPaths) > 0 {
 		zcfg.OutputPaths = cfg.OutputPaths
@@ -34,12 +79,71 @@ func Init(cfg Config) error {
 	if len(cfg.ErrorOutputPaths) > 0 {
 		zcfg.ErrorOutputPaths = cfg.ErrorOutputPaths
 	}
+	// 未开启切割时同样走 rotate sink（max_size_mb=0 不切割），外部 logrotate + SIGHUP 才能 Reopen
+	rc := cfg.Rotation
+	if !rc.Enabled {
+		rc = RotationConfig{}
+	}
+	zcfg.OutputPaths = rotatePaths(zcfg.OutputPaths, rc)
+	zcfg.ErrorOutputPaths = rotatePaths(zcfg.ErrorOutputPaths, rc)
 
-	logger, err := zcfg.Build(zap.AddCaller())
+	var enc zapcore.Encoder
+	switch zcfg.Encoding {
+	case "json":
+		enc = zapcore.NewJSONEncoder(zcfg.EncoderConfig)
+	case "console":
+		enc = zapcore.NewConsoleEncoder(zcfg.EncoderConfig)
+	default:
+		return fmt.Errorf("unknown encoding %q", zcfg.Encoding)
+	}
+	out, closeOut, err := zap.Open(zcfg.OutputPaths...)
 	if err != nil {
 		return err
 	}
+	errOut, closeErrOut, err := zap.Open(zcfg.ErrorOutputPaths...)
+	if err != nil {
+		closeOut()
+		return err
+	}
+	var aw *asyncWriter
+	if cfg.Async.Enabled {
+		aw = newAsyncWriter(out, cfg.Async)
+		out = aw
+	}
+
+	// core 链（由外到内）：levelCore -> 采样 -> 脱敏 -> tee(输出, 环形缓冲区)
+	var core zapcore.Core = zapcore.NewCore(enc, out, zapcore.DebugLevel)
+	if rb := setupRing(cfg.Ring); rb != nil {
+		core = zapcore.NewTee(core, newRingCore(rb))
+	}
+	if red != nil {
+		core = &redactCore{Core: core, r: red}
+	}
+	core = &levelCore{Core: cfg.Sampling.wrap(core)}
+
+	logger := zap.New(core,
+		zap.ErrorOutput(errOut),
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	)
+	initMu.Lock()
 	zap.ReplaceGlobals(logger)
+	// 替换后停止上一个异步 writer（剩余条目写出后退出），再关闭上一次打开的输出；
+	// 同一路径的 rotate 文件按引用计数共享，新 logger 仍在使用时不会被关闭
+	if prev := asyncOut.Swap(aw); prev != nil {
+		prev.Stop()
+	}
+	prevClose := closeOutputs
+	closeOutputs = func() {
+		closeOut()
+		closeErrOut()
+	}
+	initMu.Unlock()
+	if prevClose != nil {
+		prevClose()
+	}
+	// log/slog（以及标准库 log）的输出同样经由 zap core
+	slog.SetDefault(slog.New(NewSlogHandler()))
 	return nil
 }
 
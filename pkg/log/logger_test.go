@@ -0,0 +1,120 @@
+package log
+
+import (
+	stdlog "log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+)
+
+// initForTest 调用 Init 并在测试结束时恢复全局 logger、级别，并关闭 Init 打开的输出
+func initForTest(t *testing.T, cfg Config) {
+	t.Helper()
+	prev, prevSlog, prevOut, prevFlags := zap.L(), slog.Default(), stdlog.Writer(), stdlog.Flags()
+	if err := Init(cfg); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	t.Cleanup(func() {
+		if aw := asyncOut.Swap(nil); aw != nil {
+			aw.Stop()
+		}
+		zap.ReplaceGlobals(prev)
+		slog.SetDefault(prevSlog)
+		stdlog.SetOutput(prevOut)
+		stdlog.SetFlags(prevFlags)
+		_ = SetModuleLevels(nil)
+		levels.set("", zap.InfoLevel)
+		ring.Store(nil)
+
+		initMu.Lock()
+		closeFn := closeOutputs
+		closeOutputs = nil
+		initMu.Unlock()
+		if closeFn != nil {
+			closeFn()
+		}
+	})
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestInitReopenWithoutRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	initForTest(t, Config{Encoding: "json", OutputPaths: []string{path}})
+
+	zap.L().Info("before move")
+	Sync()
+	moved := path + ".1"
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := Reopen(); err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	zap.L().Info("after move")
+	Sync()
+
+	if got := readFile(t, moved); !strings.Contains(got, "before move") || strings.Contains(got, "after move") {
+		t.Errorf("moved file = %q", got)
+	}
+	if got := readFile(t, path); !strings.Contains(got, "after move") || strings.Contains(got, "before move") {
+		t.Errorf("reopened file = %q", got)
+	}
+}
+
+func TestInitClosesPreviousOutputs(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	initForTest(t, Config{Encoding: "json", OutputPaths: []string{first}, ErrorOutputPaths: []string{first}})
+	rotateMu.Lock()
+	old := rotateFiles[first]
+	rotateMu.Unlock()
+
+	// 同一路径重新 Init 时文件继续使用
+	initForTest(t, Config{Encoding: "json", OutputPaths: []string{first}})
+	rotateMu.Lock()
+	same, refs := rotateFiles[first], old.refs
+	rotateMu.Unlock()
+	if same != old {
+		t.Fatal("re-Init with the same path reopened the file")
+	}
+	testutil.Equal(t, refs, 1)
+	zap.L().Info("still open")
+	Sync()
+	if got := readFile(t, first); !strings.Contains(got, "still open") {
+		t.Errorf("first file = %q", got)
+	}
+
+	// 换成其他路径后，上一个文件被关闭并移出 rotateFiles
+	initForTest(t, Config{Encoding: "json", OutputPaths: []string{second}})
+	rotateMu.Lock()
+	_, kept := rotateFiles[first]
+	rotateMu.Unlock()
+	if kept {
+		t.Error("previous output is still registered")
+	}
+	old.mu.Lock()
+	closed := old.closed && old.file == nil
+	old.mu.Unlock()
+	if !closed {
+		t.Error("previous output file was not closed")
+	}
+	zap.L().Info("moved")
+	Sync()
+	if got := readFile(t, second); !strings.Contains(got, "moved") {
+		t.Errorf("second file = %q", got)
+	}
+}
@@ -0,0 +1,213 @@
+// Package logtest 在测试中捕获 pkg/log 输出的日志并提供断言：
+//
+//	func TestHandler(t *testing.T) {
+//		logs := logtest.New(t)
+//		handler.ServeHTTP(rec, req)
+//		logs.AssertLogged(logtest.Level(zapcore.ErrorLevel), logtest.Message("panic recovered"), logtest.TraceID("abc"))
+//	}
+package logtest
+
+import (
+	"fmt"
+	stdlog "log"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// Recorder 记录测试期间写入全局 logger 的日志
+type Recorder struct {
+	t    testing.TB
+	logs *observer.ObservedLogs
+}
+
+type Option func(*options)
+
+type options struct {
+	level zapcore.LevelEnabler
+}
+
+// WithLevel 只记录不低于 level 的日志，默认记录全部级别
+func WithLevel(level zapcore.Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// New 将全局 zap logger（applog.L/Named、slog 默认 handler 均经由它输出）替换为 observer，
+// 测试结束时（t.Cleanup）恢复原 logger；同时替换全局 logger 的测试不能并行执行
+func New(t testing.TB, opts ...Option) *Recorder {
+	t.Helper()
+	o := &options{level: zapcore.DebugLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+	core, logs := observer.New(o.level)
+	restore := zap.ReplaceGlobals(zap.New(core, zap.AddCaller()))
+	prevSlog, prevOut, prevFlags := slog.Default(), stdlog.Writer(), stdlog.Flags()
+	slog.SetDefault(slog.New(applog.NewSlogHandler()))
+	t.Cleanup(func() {
+		restore()
+		slog.SetDefault(prevSlog)
+		stdlog.SetOutput(prevOut)
+		stdlog.SetFlags(prevFlags)
+	})
+	return &Recorder{t: t, logs: logs}
+}
+
+// Matcher 判断一条日志是否满足条件
+type Matcher struct {
+	desc  string
+	match func(observer.LoggedEntry) bool
+}
+
+func (m Matcher) String() string {
+	return m.desc
+}
+
+func Level(level zapcore.Level) Matcher {
+	return Matcher{
+		desc:  "level=" + level.String(),
+		match: func(e observer.LoggedEntry) bool { return e.Level == level },
+	}
+}
+
+func Message(msg string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("message=%q", msg),
+		match: func(e observer.LoggedEntry) bool { return e.Message == msg },
+	}
+}
+
+func MessageContains(sub string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("message contains %q", sub),
+		match: func(e observer.LoggedEntry) bool { return strings.Contains(e.Message, sub) },
+	}
+}
+
+// Logger 匹配模块 logger 名称（applog.Named）
+func Logger(name string) Matcher {
+	return Matcher{
+		desc:  "logger=" + name,
+		match: func(e observer.LoggedEntry) bool { return e.LoggerName == name },
+	}
+}
+
+func TraceID(id string) Matcher {
+	return Field("trace_id", id)
+}
+
+// Field 匹配字段值，包括 ctx 上附加的字段（applog.WithFields）；数值按字面值比较，Field("n", 1) 可匹配 zap.Int64("n", 1)
+func Field(key string, value any) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("%s=%v", key, value),
+		match: func(e observer.LoggedEntry) bool {
+			got, ok := e.ContextMap()[key]
+			if !ok {
+				return false
+			}
+			return reflect.DeepEqual(got, value) || fmt.Sprint(got) == fmt.Sprint(value)
+		},
+	}
+}
+
+// HasField 匹配包含 key 字段的日志
+func HasField(key string) Matcher {
+	return Matcher{
+		desc: "has field " + key,
+		match: func(e observer.LoggedEntry) bool {
+			_, ok := e.ContextMap()[key]
+			return ok
+		},
+	}
+}
+
+// All 返回已记录的全部日志
+func (r *Recorder) All() []observer.LoggedEntry {
+	return r.logs.All()
+}
+
+// Filter 返回满足全部条件的日志
+func (r *Recorder) Filter(matchers ...Matcher) []observer.LoggedEntry {
+	var out []observer.LoggedEntry
+	for _, e := range r.logs.All() {
+		if matchAll(e, matchers) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Reset 清空已记录的日志
+func (r *Recorder) Reset() {
+	r.logs.TakeAll()
+}
+
+// AssertLogged 断言至少有一条日志满足全部条件，并返回第一条
+func (r *Recorder) AssertLogged(matchers ...Matcher) observer.LoggedEntry {
+	r.t.Helper()
+	found := r.Filter(matchers...)
+	if len(found) == 0 {
+		r.t.Fatalf("no log entry matches [%s]\n%s", describe(matchers), r.dump())
+		return observer.LoggedEntry{}
+	}
+	return found[0]
+}
+
+// AssertNotLogged 断言没有日志满足全部条件
+func (r *Recorder) AssertNotLogged(matchers ...Matcher) {
+	r.t.Helper()
+	if found := r.Filter(matchers...); len(found) > 0 {
+		r.t.Fatalf("%d log entries match [%s], want none\n%s", len(found), describe(matchers), r.dump())
+	}
+}
+
+// AssertCount 断言恰好有 n 条日志满足全部条件
+func (r *Recorder) AssertCount(n int, matchers ...Matcher) {
+	r.t.Helper()
+	if found := r.Filter(matchers...); len(found) != n {
+		r.t.Fatalf("%d log entries match [%s], want %d\n%s", len(found), describe(matchers), n, r.dump())
+	}
+}
+
+func matchAll(e observer.LoggedEntry, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(matchers []Matcher) string {
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = m.desc
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *Recorder) dump() string {
+	all := r.logs.All()
+	if len(all) == 0 {
+		return "recorded: <none>"
+	}
+	var b strings.Builder
+	b.WriteString("recorded:")
+	for _, e := range all {
+		fmt.Fprintf(&b, "\n  %s", e.Level.CapitalString())
+		if e.LoggerName != "" {
+			fmt.Fprintf(&b, " [%s]", e.LoggerName)
+		}
+		fmt.Fprintf(&b, " %q %v", e.Message, e.ContextMap())
+	}
+	return b.String()
+}
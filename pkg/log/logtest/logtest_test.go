@@ -0,0 +1,119 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	stdlog "log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeT 记录断言失败而不终止测试
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestNewRestoresGlobals(t *testing.T) {
+	prevZap, prevSlog := zap.L(), slog.Default()
+	prevOut, prevFlags := stdlog.Writer(), stdlog.Flags()
+
+	t.Run("capture", func(t *testing.T) {
+		logs := New(t)
+		if zap.L() == prevZap {
+			t.Fatal("global zap logger not replaced")
+		}
+		zap.L().Info("zap")
+		applog.Named("pool").Debug("named")
+		slog.Warn("slog", "k", "v")
+		stdlog.Print("stdlog")
+
+		logs.AssertLogged(Message("zap"))
+		logs.AssertLogged(Logger("pool"), Level(zapcore.DebugLevel), Message("named"))
+		logs.AssertLogged(Level(zapcore.WarnLevel), Message("slog"), Field("k", "v"))
+		logs.AssertLogged(MessageContains("stdlog"))
+		testutil.Equal(t, len(logs.All()), 4)
+	})
+
+	if zap.L() != prevZap {
+		t.Error("zap logger not restored")
+	}
+	if slog.Default() != prevSlog {
+		t.Error("slog default not restored")
+	}
+	if stdlog.Writer() != prevOut || stdlog.Flags() != prevFlags {
+		t.Error("stdlib log not restored")
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	logs := New(t, WithLevel(zapcore.WarnLevel))
+	zap.L().Info("info")
+	zap.L().Warn("warn")
+	logs.AssertCount(1)
+	logs.AssertNotLogged(Message("info"))
+}
+
+func TestMatchers(t *testing.T) {
+	logs := New(t)
+	ctx := applog.WithTraceID(context.Background(), "abc")
+	ctx = applog.WithFields(ctx, zap.String("user", "u1"))
+	applog.L(ctx).Info("request done", zap.Int64("status", 200), zap.Duration("cost", 0))
+	applog.L(ctx).Info("request done", zap.Int("status", 500))
+	zap.L().Info("other", zap.String("trace_id", "def"))
+
+	logs.AssertCount(2, TraceID("abc"))
+	logs.AssertCount(1, TraceID("def"))
+	logs.AssertCount(2, Field("user", "u1"), Message("request done"))
+	// 数值按字面值比较
+	logs.AssertCount(1, Field("status", 200))
+	logs.AssertCount(1, Field("status", int64(500)))
+	logs.AssertCount(1, HasField("cost"))
+	logs.AssertCount(3, MessageContains("e"))
+	logs.AssertCount(0, TraceID("abc"), Field("status", 404))
+
+	e := logs.AssertLogged(TraceID("abc"), Field("status", 500))
+	testutil.Equal(t, e.ContextMap()["user"], any("u1"))
+
+	logs.Reset()
+	logs.AssertCount(0)
+}
+
+func TestAssertionFailures(t *testing.T) {
+	logs := New(t)
+	zap.L().Named("http").Info("served", zap.String("trace_id", "abc"))
+
+	ft := &fakeT{TB: t}
+	logs.t = ft
+	logs.AssertLogged(TraceID("def"))
+	logs.AssertCount(2, TraceID("abc"))
+	logs.AssertNotLogged(Logger("http"))
+	logs.AssertLogged(Message("served"), TraceID("abc"))
+
+	testutil.Equal(t, len(ft.failures), 3)
+	for i, want := range []string{
+		"no log entry matches [trace_id=def]",
+		"1 log entries match [trace_id=abc], want 2",
+		"1 log entries match [logger=http], want none",
+	} {
+		if !strings.HasPrefix(ft.failures[i], want) {
+			t.Errorf("failure %d = %q, want prefix %q", i, ft.failures[i], want)
+		}
+		if !strings.Contains(ft.failures[i], `INFO [http] "served" map[trace_id:abc]`) {
+			t.Errorf("failure %d does not list recorded entries: %q", i, ft.failures[i])
+		}
+	}
+}
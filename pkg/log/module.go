@@ -0,0 +1,79 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var modules sync.Map // map[string]*moduleLogger
+
+type moduleLogger struct {
+	name   string
+	cached cachedLoggerRef
+}
+
+// Named 返回模块 logger（等价于 zap.L().Named(name)，按名称缓存，Init 后自动重建），
+// 其级别可通过 log.modules 配置或 SetLoggerLevel 单独调整，子模块（"pool.worker"）继承父模块级别
+func Named(name string) *zap.Logger {
+	v, ok := modules.Load(name)
+	if !ok {
+		v, _ = modules.LoadOrStore(name, &moduleLogger{name: name})
+	}
+	m := v.(*moduleLogger)
+	return m.cached.get(func(base *zap.Logger) *zap.Logger {
+		return base.Named(m.name)
+	})
+}
+
+// NamedL 返回带 ctx 字段（trace_id 等）的模块 logger，与 L(ctx) 一样按 ctx 缓存
+func NamedL(ctx context.Context, name string) *zap.Logger {
+	f := fieldsFrom(ctx)
+	if f == nil {
+		return Named(name)
+	}
+	ref, ok := f.named.Load(name)
+	if !ok {
+		ref, _ = f.named.LoadOrStore(name, &cachedLoggerRef{})
+	}
+	return ref.(*cachedLoggerRef).get(func(base *zap.Logger) *zap.Logger {
+		return base.Named(name).With(f.fields...)
+	})
+}
+
+var (
+	moduleMu   sync.Mutex
+	configured map[string]bool
+)
+
+// SetModuleLevels 以配置中的 modules 替换之前由配置设置的模块级别，
+// 配置中已移除的模块恢复跟随全局级别；通过 SetLoggerLevel 设置的其他覆盖不受影响
+func SetModuleLevels(levelsByModule map[string]string) error {
+	parsed := make(map[string]string, len(levelsByModule))
+	for name, level := range levelsByModule {
+		if name == "" {
+			return fmt.Errorf("modules: empty module name")
+		}
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("modules.%s: %w", name, err)
+		}
+		parsed[name] = level
+	}
+
+	moduleMu.Lock()
+	defer moduleMu.Unlock()
+	for name := range configured {
+		if _, ok := parsed[name]; !ok {
+			ResetLoggerLevel(name)
+		}
+	}
+	next := make(map[string]bool, len(parsed))
+	for name, level := range parsed {
+		_ = SetLoggerLevel(name, level)
+		next[name] = true
+	}
+	configured = next
+	return nil
+}
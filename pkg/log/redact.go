@@ -0,0 +1,447 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultMask = "******"
+
+// RedactConfig 配置日志脱敏：Keys 为字段名通配模式（大小写不敏感，如 "*token*"），命中的字段整体替换为 Mask；
+// Patterns 为正则，命中的片段在字符串字段、error 与日志消息中替换为 Mask
+type RedactConfig struct {
+	Keys     []string `mapstructure:"keys" yaml:"keys" default:"*password*,*secret*,*token*,authorization,cookie"`
+	Patterns []string `mapstructure:"patterns" yaml:"patterns"`
+	Mask     string   `mapstructure:"mask" yaml:"mask" default:"******"`
+}
+
+type redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	mask     string
+}
+
+// newRedactor 编译脱敏规则，未配置任何规则时返回 nil
+func newRedactor(cfg RedactConfig) (*redactor, error) {
+	if len(cfg.Keys) == 0 && len(cfg.Patterns) == 0 {
+		return nil, nil
+	}
+	r := &redactor{mask: cfg.Mask}
+	if r.mask == "" {
+		r.mask = defaultMask
+	}
+	for _, k := range cfg.Keys {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			continue
+		}
+		if _, err := path.Match(k, ""); err != nil {
+			return nil, fmt.Errorf("key pattern %q: %w", k, err)
+		}
+		r.keys = append(r.keys, k)
+	}
+	for _, p := range cfg.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if ok, _ := path.Match(k, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) text(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.mask)
+	}
+	return s
+}
+
+func (r *redactor) field(f zapcore.Field) (zapcore.Field, bool) {
+	if f.Type == zapcore.SkipType || f.Type == zapcore.NamespaceType {
+		return f, false
+	}
+	if r.matchKey(f.Key) {
+		return zap.String(f.Key, r.mask), true
+	}
+	// 嵌套结构（zap.Object/zap.Dict、slog group、zap.Array）在编码时经由包装后的 encoder 逐层脱敏
+	switch f.Type {
+	case zapcore.ObjectMarshalerType:
+		return zap.Object(f.Key, redactObject{m: f.Interface.(zapcore.ObjectMarshaler), r: r}), true
+	case zapcore.InlineMarshalerType:
+		return zap.Inline(redactObject{m: f.Interface.(zapcore.ObjectMarshaler), r: r}), true
+	case zapcore.ArrayMarshalerType:
+		return zap.Array(f.Key, redactArray{a: f.Interface.(zapcore.ArrayMarshaler), r: r}), true
+	case zapcore.ReflectType:
+		if v, ok := r.reflected(f.Interface); ok {
+			return zap.Any(f.Key, v), true
+		}
+	}
+	if len(r.patterns) == 0 {
+		return f, false
+	}
+	var s string
+	switch f.Type {
+	case zapcore.StringType:
+		s = f.String
+	case zapcore.ByteStringType:
+		s = string(f.Interface.([]byte))
+	case zapcore.ErrorType, zapcore.StringerType, zapcore.ReflectType:
+		s = fmt.Sprint(f.Interface)
+	default:
+		return f, false
+	}
+	if red := r.text(s); red != s {
+		return zap.String(f.Key, red), true
+	}
+	return f, false
+}
+
+// reflected 处理 zap.Any 传入的 map、切片与结构体（含任意层嵌套）：屏蔽命中 Keys 的 map 条目与结构体字段，
+// 对字符串应用 Patterns；无需修改时返回 false
+func (r *redactor) reflected(v any) (any, bool) {
+	switch v := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		red := r.text(v)
+		return red, red != v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		out := make(map[string]any, rv.Len())
+		changed := false
+		iter := rv.MapRange()
+		for iter.Next() {
+			k, val := iter.Key().String(), iter.Value().Interface()
+			if r.matchKey(k) {
+				out[k], changed = r.mask, true
+				continue
+			}
+			red, ok := r.reflected(val)
+			out[k], changed = red, changed || ok
+		}
+		return out, changed
+	case reflect.Struct:
+		return r.structured(v)
+	case reflect.Pointer:
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return v, false
+		}
+		return r.structured(v)
+	case reflect.Slice, reflect.Array:
+		switch rv.Type().Elem().Kind() {
+		case reflect.String, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		default:
+			return v, false
+		}
+		out := make([]any, rv.Len())
+		changed := false
+		for i := range out {
+			red, ok := r.reflected(rv.Index(i).Interface())
+			out[i], changed = red, changed || ok
+		}
+		return out, changed
+	}
+	return v, false
+}
+
+// structured 经 JSON 往返将结构体转为 map 后脱敏，字段名以 json tag 为准，与编码输出一致；
+// 无需修改或无法编码时原样返回
+func (r *redactor) structured(v any) (any, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return v, false
+	}
+	if red, ok := r.reflected(generic); ok {
+		return red, true
+	}
+	return v, false
+}
+
+// fields 返回脱敏后的字段，无需修改时返回原切片
+func (r *redactor) fields(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, f := range fields {
+		red, changed := r.field(f)
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]zapcore.Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, red)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// redactCore 在写出前对消息与字段脱敏，位于采样器与输出 core 之间
+type redactCore struct {
+	zapcore.Core
+	r *redactor
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.r.fields(fields)), r: c.r}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.r.text(ent.Message)
+	return c.Core.Write(ent, c.r.fields(fields))
+}
+
+// redactObject 包装 ObjectMarshaler，编码时由 redactEncoder 对其中的字段脱敏
+type redactObject struct {
+	m zapcore.ObjectMarshaler
+	r *redactor
+}
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.m.MarshalLogObject(&redactEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+type redactArray struct {
+	a zapcore.ArrayMarshaler
+	r *redactor
+}
+
+func (a redactArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.a.MarshalLogArray(&redactArrayEncoder{ArrayEncoder: enc, r: a.r})
+}
+
+// redactEncoder 在写入底层 ObjectEncoder 前按 key 屏蔽字段、按 Patterns 替换字符串，嵌套对象递归包装
+type redactEncoder struct {
+	zapcore.ObjectEncoder
+	r *redactor
+}
+
+// masked 命中 Keys 时写入掩码并返回 true
+func (e *redactEncoder) masked(key string) bool {
+	if !e.r.matchKey(key) {
+		return false
+	}
+	e.ObjectEncoder.AddString(key, e.r.mask)
+	return true
+}
+
+func (e *redactEncoder) AddArray(key string, a zapcore.ArrayMarshaler) error {
+	if e.masked(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, redactArray{a: a, r: e.r})
+}
+
+func (e *redactEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	if e.masked(key) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactObject{m: m, r: e.r})
+}
+
+func (e *redactEncoder) AddReflected(key string, v any) error {
+	if e.masked(key) {
+		return nil
+	}
+	if red, ok := e.r.reflected(v); ok {
+		v = red
+	}
+	return e.ObjectEncoder.AddReflected(key, v)
+}
+
+func (e *redactEncoder) AddString(key, v string) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddString(key, e.r.text(v))
+	}
+}
+
+func (e *redactEncoder) AddByteString(key string, v []byte) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddString(key, e.r.text(string(v)))
+	}
+}
+
+func (e *redactEncoder) AddBinary(key string, v []byte) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddBinary(key, v)
+	}
+}
+
+func (e *redactEncoder) AddBool(key string, v bool) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddBool(key, v)
+	}
+}
+
+func (e *redactEncoder) AddComplex128(key string, v complex128) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddComplex128(key, v)
+	}
+}
+
+func (e *redactEncoder) AddComplex64(key string, v complex64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddComplex64(key, v)
+	}
+}
+
+func (e *redactEncoder) AddDuration(key string, v time.Duration) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddDuration(key, v)
+	}
+}
+
+func (e *redactEncoder) AddFloat64(key string, v float64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddFloat64(key, v)
+	}
+}
+
+func (e *redactEncoder) AddFloat32(key string, v float32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddFloat32(key, v)
+	}
+}
+
+func (e *redactEncoder) AddInt(key string, v int) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt(key, v)
+	}
+}
+
+func (e *redactEncoder) AddInt64(key string, v int64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt64(key, v)
+	}
+}
+
+func (e *redactEncoder) AddInt32(key string, v int32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt32(key, v)
+	}
+}
+
+func (e *redactEncoder) AddInt16(key string, v int16) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt16(key, v)
+	}
+}
+
+func (e *redactEncoder) AddInt8(key string, v int8) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddInt8(key, v)
+	}
+}
+
+func (e *redactEncoder) AddTime(key string, v time.Time) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddTime(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUint(key string, v uint) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUint64(key string, v uint64) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint64(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUint32(key string, v uint32) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint32(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUint16(key string, v uint16) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint16(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUint8(key string, v uint8) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUint8(key, v)
+	}
+}
+
+func (e *redactEncoder) AddUintptr(key string, v uintptr) {
+	if !e.masked(key) {
+		e.ObjectEncoder.AddUintptr(key, v)
+	}
+}
+
+// redactArrayEncoder 对数组元素中的字符串与嵌套对象脱敏
+type redactArrayEncoder struct {
+	zapcore.ArrayEncoder
+	r *redactor
+}
+
+func (e *redactArrayEncoder) AppendString(v string) {
+	e.ArrayEncoder.AppendString(e.r.text(v))
+}
+
+func (e *redactArrayEncoder) AppendByteString(v []byte) {
+	e.ArrayEncoder.AppendString(e.r.text(string(v)))
+}
+
+func (e *redactArrayEncoder) AppendArray(a zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(redactArray{a: a, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendObject(m zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(redactObject{m: m, r: e.r})
+}
+
+func (e *redactArrayEncoder) AppendReflected(v any) error {
+	if red, ok := e.r.reflected(v); ok {
+		v = red
+	}
+	return e.ArrayEncoder.AppendReflected(v)
+}
@@ -0,0 +1,188 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type credentials struct {
+	User     string
+	Password string
+	Inner    *credentials
+}
+
+func (c credentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", c.User)
+	enc.AddString("password", c.Password)
+	if c.Inner != nil {
+		return enc.AddObject("inner", c.Inner)
+	}
+	return nil
+}
+
+// account 未实现 ObjectMarshaler，经 zap.Any 以反射方式编码
+type account struct {
+	Name     string   `json:"name"`
+	Password string   `json:"password"`
+	Age      int      `json:"age"`
+	Parent   *account `json:"parent,omitempty"`
+}
+
+// redactLogger 返回经 redactCore 输出 JSON 的 logger，以及解析最后一行的函数
+func redactLogger(t *testing.T, cfg RedactConfig) (*zap.Logger, func() map[string]any) {
+	t.Helper()
+	r, err := newRedactor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	core := &redactCore{Core: zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel), r: r}
+	last := func() map[string]any {
+		t.Helper()
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		var out map[string]any
+		if err := json.Unmarshal(lines[len(lines)-1], &out); err != nil {
+			t.Fatalf("unmarshal %q: %v", lines[len(lines)-1], err)
+		}
+		return out
+	}
+	return zap.New(core), last
+}
+
+func TestRedact(t *testing.T) {
+	logger, last := redactLogger(t, RedactConfig{
+		Keys:     []string{"*password*", "token"},
+		Patterns: []string{`sk-[a-z0-9]+`},
+	})
+	const mask = defaultMask
+	tests := []struct {
+		name  string
+		log   func()
+		check map[string]any
+	}{
+		{
+			name: "top-level key and pattern",
+			log: func() {
+				logger.Info("key sk-abc123", zap.String("db_password", "p"), zap.String("note", "use sk-abc123"))
+			},
+			check: map[string]any{"msg": "key " + mask, "db_password": mask, "note": "use " + mask},
+		},
+		{
+			name:  "error value",
+			log:   func() { logger.Info("failed", zap.Error(errors.New("bad key sk-abc"))) },
+			check: map[string]any{"error": "bad key " + mask},
+		},
+		{
+			name: "nested map",
+			log: func() {
+				logger.Info("m", zap.Any("req", map[string]any{
+					"user":    "u1",
+					"headers": map[string]string{"token": "t", "accept": "json"},
+					"body":    map[string]any{"items": []any{map[string]any{"password": "p"}, "sk-x1"}},
+				}))
+			},
+			check: map[string]any{"req": map[string]any{
+				"user":    "u1",
+				"headers": map[string]any{"token": mask, "accept": "json"},
+				"body":    map[string]any{"items": []any{map[string]any{"password": mask}, mask}},
+			}},
+		},
+		{
+			name: "slice of strings",
+			log: func() {
+				logger.Info("s", zap.Any("args", []string{"--key", "sk-abc"}), zap.Strings("list", []string{"sk-def", "ok"}))
+			},
+			check: map[string]any{"args": []any{"--key", mask}, "list": []any{mask, "ok"}},
+		},
+		{
+			name: "structs",
+			log: func() {
+				logger.Info("st", zap.Any("acct", account{Name: "u", Password: "p", Age: 30, Parent: &account{Name: "sk-abc", Password: "q"}}),
+					zap.Any("ptr", &account{Name: "v", Password: "p"}), zap.Any("list", []account{{Name: "w", Password: "p"}}))
+			},
+			check: map[string]any{
+				"acct": map[string]any{"name": "u", "password": mask, "age": 30.0,
+					"parent": map[string]any{"name": mask, "password": mask, "age": 0.0}},
+				"ptr":  map[string]any{"name": "v", "password": mask, "age": 0.0},
+				"list": []any{map[string]any{"name": "w", "password": mask, "age": 0.0}},
+			},
+		},
+		{
+			name: "object marshaler",
+			log: func() {
+				logger.Info("o", zap.Object("cred", credentials{User: "u", Password: "p", Inner: &credentials{User: "sk-abc", Password: "q"}}))
+			},
+			check: map[string]any{"cred": map[string]any{
+				"user": "u", "password": mask,
+				"inner": map[string]any{"user": mask, "password": mask},
+			}},
+		},
+		{
+			name: "dict and inline",
+			log: func() {
+				logger.Info("d", zap.Dict("auth", zap.String("token", "t"), zap.Int("password_len", 8)), zap.Inline(credentials{User: "u", Password: "p"}))
+			},
+			check: map[string]any{"auth": map[string]any{"token": mask, "password_len": mask}, "user": "u", "password": mask},
+		},
+		{
+			name: "array of objects",
+			log: func() {
+				logger.Info("a", zap.Objects("creds", []credentials{{User: "a", Password: "p"}}))
+			},
+			check: map[string]any{"creds": []any{map[string]any{"user": "a", "password": mask}}},
+		},
+		{
+			name:  "with fields",
+			log:   func() { logger.With(zap.Object("cred", credentials{User: "u", Password: "p"})).Info("w") },
+			check: map[string]any{"cred": map[string]any{"user": "u", "password": mask}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+			got := last()
+			for k, want := range tt.check {
+				testutil.Equal(t, got[k], want)
+			}
+		})
+	}
+}
+
+func TestRedactSlogGroups(t *testing.T) {
+	logger, last := redactLogger(t, RedactConfig{Keys: []string{"*password*", "token"}})
+	t.Cleanup(zap.ReplaceGlobals(logger))
+
+	sl := slog.New(NewSlogHandler())
+	sl.Info("login", slog.Group("req", slog.String("user", "u"), slog.String("password", "p"),
+		slog.Group("headers", slog.String("token", "t")), slog.Any("meta", map[string]any{"token": "t"})))
+	testutil.Equal(t, last()["req"], any(map[string]any{
+		"user": "u", "password": defaultMask,
+		"headers": map[string]any{"token": defaultMask},
+		"meta":    map[string]any{"token": defaultMask},
+	}))
+
+	sl.WithGroup("session").Info("refresh", slog.String("token", "t"), slog.Int("ttl", 60))
+	testutil.Equal(t, last()["session"], any(map[string]any{"token": defaultMask, "ttl": 60.0}))
+}
+
+func TestRedactUnchangedFields(t *testing.T) {
+	r, err := newRedactor(RedactConfig{Keys: []string{"token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := []zapcore.Field{zap.String("user", "u"), zap.Int("n", 1), zap.Any("m", map[string]int{"n": 1}),
+		zap.Any("acct", account{Name: "u"})}
+	out := r.fields(fields)
+	if &out[0] != &fields[0] {
+		t.Error("fields without sensitive values were copied")
+	}
+}
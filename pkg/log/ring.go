@@ -0,0 +1,299 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// RingConfig 开启后，日志条目（脱敏后）同时写入内存环形缓冲区，可通过 RingHandler 查询最近的日志
+type RingConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	Size    int  `mapstructure:"size" yaml:"size" default:"2000" validate:"min=0"`
+}
+
+// Record 是环形缓冲区中保存的一条日志
+type Record struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Logger  string
+	Caller  string
+	Message string
+	TraceID string
+	Fields  map[string]any
+
+	fields []byte // 写入时编码的字段（JSON 对象），查询时解码为 Fields，避免引用调用方的数据
+}
+
+// recordKeys 是 Record 输出 JSON 时占用的键，同名的日志字段改以 "fields." 前缀输出
+var recordKeys = map[string]bool{"ts": true, "level": true, "msg": true, "logger": true, "caller": true}
+
+func (r Record) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(r.Fields)+5)
+	for k, v := range r.Fields {
+		if recordKeys[k] {
+			k = "fields." + k
+		}
+		m[k] = v
+	}
+	m["ts"] = r.Time.Format(time.RFC3339Nano)
+	m["level"] = r.Level.String()
+	m["msg"] = r.Message
+	if r.Logger != "" {
+		m["logger"] = r.Logger
+	}
+	if r.Caller != "" {
+		m["caller"] = r.Caller
+	}
+	return json.Marshal(m)
+}
+
+type ringBuffer struct {
+	mu   sync.Mutex
+	buf  []Record
+	next int
+	full bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{buf: make([]Record, size)}
+}
+
+func (b *ringBuffer) add(r Record) {
+	b.mu.Lock()
+	b.buf[b.next] = r
+	b.next++
+	if b.next == len(b.buf) {
+		b.next = 0
+		b.full = true
+	}
+	b.mu.Unlock()
+}
+
+// snapshot 按时间先后返回满足条件的记录，limit > 0 时只保留最新的 limit 条
+func (b *ringBuffer) snapshot(match func(Record) bool, limit int) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var ordered []Record
+	if b.full {
+		ordered = append(ordered, b.buf[b.next:]...)
+	}
+	ordered = append(ordered, b.buf[:b.next]...)
+	out := ordered[:0]
+	for _, r := range ordered {
+		if match(r) {
+			out = append(out, r)
+		}
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out
+}
+
+var ring atomic.Pointer[ringBuffer]
+
+// setupRing 按配置创建或复用缓冲区，大小不变时 Init 重建 logger 不会清空已有记录
+func setupRing(cfg RingConfig) *ringBuffer {
+	if !cfg.Enabled || cfg.Size <= 0 {
+		ring.Store(nil)
+		return nil
+	}
+	if b := ring.Load(); b != nil && len(b.buf) == cfg.Size {
+		return b
+	}
+	b := newRingBuffer(cfg.Size)
+	ring.Store(b)
+	return b
+}
+
+// ringEncoderConfig 只编码字段，时间、级别、消息等由 Record 单独保存
+var ringEncoderConfig = zapcore.EncoderConfig{
+	EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+	EncodeDuration: zapcore.StringDurationEncoder,
+}
+
+// ringCore 在写入时把字段编码为字节，缓冲区中的记录不持有调用方的 map、切片或对象
+type ringCore struct {
+	buf     *ringBuffer
+	enc     zapcore.Encoder
+	traceID string
+}
+
+func newRingCore(buf *ringBuffer) *ringCore {
+	return &ringCore{buf: buf, enc: zapcore.NewJSONEncoder(ringEncoderConfig)}
+}
+
+func (c *ringCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *ringCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &ringCore{buf: c.buf, enc: c.enc.Clone(), traceID: traceIDOf(fields, c.traceID)}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+func (c *ringCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *ringCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	out, err := c.enc.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return err
+	}
+	rec := Record{
+		Time:    ent.Time,
+		Level:   ent.Level,
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		TraceID: traceIDOf(fields, c.traceID),
+		fields:  append([]byte(nil), out.Bytes()...),
+	}
+	out.Free()
+	if ent.Caller.Defined {
+		rec.Caller = ent.Caller.TrimmedPath()
+	}
+	c.buf.add(rec)
+	return nil
+}
+
+func (c *ringCore) Sync() error {
+	return nil
+}
+
+// traceIDOf 返回 fields 中最后一个 trace_id 字段的值，没有时返回 def
+func traceIDOf(fields []zapcore.Field, def string) string {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if f := fields[i]; f.Key == "trace_id" && f.Type == zapcore.StringType {
+			return f.String
+		}
+	}
+	return def
+}
+
+// decodeFields 将写入时编码的字段解码为新的 map，数字保留为 json.Number
+func decodeFields(b []byte) map[string]any {
+	fields := make(map[string]any)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	_ = dec.Decode(&fields)
+	return fields
+}
+
+// RingFilter 描述环形缓冲区的查询条件，零值表示不过滤
+type RingFilter struct {
+	Level   *zapcore.Level // 最低级别
+	TraceID string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func (f RingFilter) match(r Record) bool {
+	if f.Level != nil && r.Level < *f.Level {
+		return false
+	}
+	if f.TraceID != "" && r.TraceID != f.TraceID {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && r.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// RingRecords 返回环形缓冲区中满足条件的记录；未开启缓冲区时返回 nil
+func RingRecords(f RingFilter) []Record {
+	b := ring.Load()
+	if b == nil {
+		return nil
+	}
+	recs := b.snapshot(f.match, f.Limit)
+	for i := range recs {
+		recs[i].Fields = decodeFields(recs[i].fields)
+	}
+	return recs
+}
+
+// RingHandler 以 JSON Lines 返回最近的日志，支持查询参数：
+//
+//	level=warn                     最低级别
+//	trace_id=abc                   按 trace_id 过滤
+//	since=5m | since=<RFC3339>     起始时间（相对当前时间或绝对时间）
+//	until=<RFC3339>                结束时间
+//	limit=100                      只返回最新的 N 条
+func RingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if ring.Load() == nil {
+			writeError(w, http.StatusNotFound, errors.New("log ring buffer is disabled"))
+			return
+		}
+		f, err := parseRingFilter(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, rec := range RingRecords(f) {
+			if err := enc.Encode(rec); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func parseRingFilter(r *http.Request) (RingFilter, error) {
+	q := r.URL.Query()
+	f := RingFilter{TraceID: q.Get("trace_id")}
+	if v := q.Get("level"); v != "" {
+		lvl, err := parseLevel(v)
+		if err != nil {
+			return f, fmt.Errorf("level: %w", err)
+		}
+		f.Level = &lvl
+	}
+	var err error
+	if f.Since, err = parseRingTime(q.Get("since")); err != nil {
+		return f, fmt.Errorf("since: %w", err)
+	}
+	if f.Until, err = parseRingTime(q.Get("until")); err != nil {
+		return f, fmt.Errorf("until: %w", err)
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit < 0 {
+			return f, fmt.Errorf("limit: invalid value %q", v)
+		}
+	}
+	return f, nil
+}
+
+// parseRingTime 支持相对时长（"5m" 表示 5 分钟前）与 RFC3339 时间
+func parseRingTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
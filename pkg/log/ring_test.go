@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type mutableObject struct {
+	name string
+}
+
+func (o *mutableObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", o.name)
+	return nil
+}
+
+// ringLogger 开启全局环形缓冲区并返回只写入该缓冲区的 logger
+func ringLogger(t *testing.T, size int) *zap.Logger {
+	t.Helper()
+	rb := setupRing(RingConfig{Enabled: true, Size: size})
+	t.Cleanup(func() { ring.Store(nil) })
+	return zap.New(newRingCore(rb), zap.AddCaller())
+}
+
+func TestRingRecordsDetachedFromCaller(t *testing.T) {
+	logger := ringLogger(t, 10)
+	m := map[string]any{"n": 1, "tags": []string{"a"}}
+	tags := []string{"x"}
+	obj := &mutableObject{name: "before"}
+	logger.Info("detached", zap.Any("m", m), zap.Strings("tags", tags), zap.Object("obj", obj), zap.Duration("cost", time.Second))
+
+	m["n"] = 2
+	m["added"] = true
+	tags[0] = "y"
+	obj.name = "after"
+
+	recs := RingRecords(RingFilter{})
+	testutil.Equal(t, len(recs), 1)
+	testutil.Equal(t, recs[0].Fields, map[string]any{
+		"m":    map[string]any{"n": json.Number("1"), "tags": []any{"a"}},
+		"tags": []any{"x"},
+		"obj":  map[string]any{"name": "before"},
+		"cost": "1s",
+	})
+	// 每次查询返回新的 map，修改不会影响缓冲区
+	recs[0].Fields["obj"] = nil
+	testutil.Equal(t, RingRecords(RingFilter{})[0].Fields["obj"], any(map[string]any{"name": "before"}))
+}
+
+func TestRingFilter(t *testing.T) {
+	logger := ringLogger(t, 3)
+	logger.Debug("dropped by size")
+	logger.With(zap.String("trace_id", "abc")).Info("first")
+	logger.Warn("second", zap.String("trace_id", "def"))
+	logger.With(zap.String("trace_id", "abc")).Error("third", zap.Int("n", 3))
+
+	messages := func(f RingFilter) []string {
+		var out []string
+		for _, r := range RingRecords(f) {
+			out = append(out, r.Message)
+		}
+		return out
+	}
+	warn := zapcore.WarnLevel
+	testutil.Equal(t, messages(RingFilter{}), []string{"first", "second", "third"})
+	testutil.Equal(t, messages(RingFilter{TraceID: "abc"}), []string{"first", "third"})
+	testutil.Equal(t, messages(RingFilter{Level: &warn}), []string{"second", "third"})
+	testutil.Equal(t, messages(RingFilter{Limit: 1}), []string{"third"})
+	testutil.Equal(t, messages(RingFilter{Since: time.Now().Add(time.Minute)}), []string(nil))
+}
+
+func TestRingHandler(t *testing.T) {
+	logger := ringLogger(t, 10)
+	logger.Info("hello", zap.String("trace_id", "abc"), zap.Int("n", 1))
+	logger.Info("other")
+
+	rec := httptest.NewRecorder()
+	RingHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/recent?trace_id=abc", nil))
+	testutil.Equal(t, rec.Code, http.StatusOK)
+	sc := bufio.NewScanner(rec.Body)
+	var lines []map[string]any
+	for sc.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &line); err != nil {
+			t.Fatalf("unmarshal %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	testutil.Equal(t, len(lines), 1)
+	testutil.Equal(t, lines[0]["msg"], any("hello"))
+	testutil.Equal(t, lines[0]["n"], any(1.0))
+	if caller, _ := lines[0]["caller"].(string); !strings.HasPrefix(caller, "log/ring_test.go:") {
+		t.Errorf("caller = %q", caller)
+	}
+
+	for _, query := range []string{"level=loud", "since=yesterday", "limit=-1"} {
+		rec = httptest.NewRecorder()
+		RingHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/recent?"+query, nil))
+		testutil.Equal(t, rec.Code, http.StatusBadRequest)
+	}
+	ring.Store(nil)
+	rec = httptest.NewRecorder()
+	RingHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log/recent", nil))
+	testutil.Equal(t, rec.Code, http.StatusNotFound)
+}
+
+func TestRecordMarshalJSONKeepsCollidingFields(t *testing.T) {
+	r := Record{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   zapcore.WarnLevel,
+		Message: "hello",
+		Fields:  map[string]any{"msg": "user msg", "level": 3, "caller": "c", "n": 1},
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	testutil.Equal(t, got, map[string]any{
+		"ts": "2024-01-02T03:04:05Z", "level": "warn", "msg": "hello", "n": 1.0,
+		"fields.msg": "user msg", "fields.level": 3.0, "fields.caller": "c",
+	})
+}
@@ -0,0 +1,365 @@
+package log
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	rotateScheme     = "rotate"
+	backupTimeFormat = "20060102T150405.000"
+)
+
+type RotationConfig struct {
+	Enabled    bool `mapstructure:"enabled" yaml:"enabled"`
+	MaxSizeMB  int  `mapstructure:"max_size_mb" yaml:"max_size_mb" default:"100" validate:"min=0"`
+	MaxAgeDays int  `mapstructure:"max_age_days" yaml:"max_age_days" validate:"min=0"`
+	MaxBackups int  `mapstructure:"max_backups" yaml:"max_backups" validate:"min=0"`
+	Compress   bool `mapstructure:"compress" yaml:"compress"`
+}
+
+var (
+	rotateMu    sync.Mutex
+	rotateFiles = make(map[string]*rotatingFile)
+)
+
+func init() {
+	if err := zap.RegisterSink(rotateScheme, newRotateSink); err != nil {
+		panic(err)
+	}
+}
+
+// rotateURL 将普通文件路径转换为 rotate sink 地址，stdout/stderr 与已带 scheme 的地址保持不变
+func rotateURL(path string, rc RotationConfig) string {
+	if path == "stdout" || path == "stderr" || strings.Contains(path, "://") || strings.HasPrefix(path, rotateScheme+":") {
+		return path
+	}
+	q := url.Values{}
+	q.Set("max_size_mb", strconv.Itoa(rc.MaxSizeMB))
+	q.Set("max_age_days", strconv.Itoa(rc.MaxAgeDays))
+	q.Set("max_backups", strconv.Itoa(rc.MaxBackups))
+	q.Set("compress", strconv.FormatBool(rc.Compress))
+	u := url.URL{Scheme: rotateScheme, Opaque: filepath.ToSlash(path), RawQuery: q.Encode()}
+	return u.String()
+}
+
+func rotatePaths(paths []string, rc RotationConfig) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = rotateURL(p, rc)
+	}
+	return out
+}
+
+// newRotateSink 解析 rotate:<path>?max_size_mb=&max_age_days=&max_backups=&compress=，
+// 同一路径复用同一个文件句柄（输出与错误输出可指向同一文件），每次返回都增加一次引用
+func newRotateSink(u *url.URL) (zap.Sink, error) {
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, errors.New("rotate sink: empty path")
+	}
+	path = filepath.Clean(filepath.FromSlash(path))
+
+	q := u.Query()
+	intParam := func(name string) (int, error) {
+		v := q.Get(name)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("rotate sink: invalid %s %q", name, v)
+		}
+		return n, nil
+	}
+	maxSize, err := intParam("max_size_mb")
+	if err != nil {
+		return nil, err
+	}
+	maxAge, err := intParam("max_age_days")
+	if err != nil {
+		return nil, err
+	}
+	maxBackups, err := intParam("max_backups")
+	if err != nil {
+		return nil, err
+	}
+	compress := q.Get("compress") == "true"
+
+	rotateMu.Lock()
+	defer rotateMu.Unlock()
+	if f, ok := rotateFiles[path]; ok {
+		f.configure(maxSize, maxAge, maxBackups, compress)
+		f.refs++
+		return f, nil
+	}
+	f := &rotatingFile{path: path, refs: 1}
+	f.configure(maxSize, maxAge, maxBackups, compress)
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	rotateFiles[path] = f
+	return f, nil
+}
+
+type rotatingFile struct {
+	refs int // 由 rotateMu 保护
+
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+	file       *os.File
+	size       int64
+	closed     bool
+
+	cleanupMu sync.Mutex
+}
+
+func (f *rotatingFile) configure(maxSizeMB, maxAgeDays, maxBackups int, compress bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxSize = int64(maxSizeMB) * 1024 * 1024
+	f.maxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+	f.maxBackups = maxBackups
+	f.compress = compress
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close 释放一次引用，最后一个引用释放时关闭文件，之后同一路径会重新打开新的句柄
+func (f *rotatingFile) Close() error {
+	rotateMu.Lock()
+	f.refs--
+	last := f.refs <= 0
+	if last && rotateFiles[f.path] == f {
+		delete(rotateFiles, f.path)
+	}
+	rotateMu.Unlock()
+	if !last {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Reopen 关闭并重新打开文件，配合外部 logrotate 的 move + SIGHUP 使用
+func (f *rotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	ext := filepath.Ext(f.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), time.Now().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.path, backup); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	go f.cleanup(f.maxBackups, f.maxAge, f.compress)
+	return nil
+}
+
+// cleanup 压缩旧文件并按数量、时间清理，在后台执行避免阻塞写入
+func (f *rotatingFile) cleanup(maxBackups int, maxAge time.Duration, compress bool) {
+	f.cleanupMu.Lock()
+	defer f.cleanupMu.Unlock()
+
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for i, b := range backups {
+		if (maxBackups > 0 && i >= maxBackups) || (maxAge > 0 && b.ModTime().Before(cutoff)) {
+			_ = os.Remove(filepath.Join(filepath.Dir(f.path), b.Name()))
+			continue
+		}
+		if compress && !strings.HasSuffix(b.Name(), ".gz") {
+			_ = gzipFile(filepath.Join(filepath.Dir(f.path), b.Name()))
+		}
+	}
+}
+
+// backups 返回按修改时间从新到旧排序的备份文件
+func (f *rotatingFile) backups() ([]os.FileInfo, error) {
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []os.FileInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)[len(prefix):]
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ModTime().After(out[j].ModTime()) })
+	return out, nil
+}
+
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// Reopen 重新打开所有 rotate sink 的文件
+func Reopen() error {
+	rotateMu.Lock()
+	files := make([]*rotatingFile, 0, len(rotateFiles))
+	for _, f := range rotateFiles {
+		files = append(files, f)
+	}
+	rotateMu.Unlock()
+
+	var errs []error
+	for _, f := range files {
+		if err := f.Reopen(); err != nil {
+			errs = append(errs, fmt.Errorf("reopen %s: %w", f.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReopenOnSignal 收到 SIGHUP 时重新打开日志文件，直到 ctx 结束
+func ReopenOnSignal(ctx context.Context) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if err := Reopen(); err != nil {
+					zap.L().Error("reopen log files failed", zap.Error(err))
+					continue
+				}
+				zap.L().Info("log files reopened")
+			}
+		}
+	}()
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+)
+
+func openRotateSink(t *testing.T, rawURL string) *rotatingFile {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := newRotateSink(u)
+	if err != nil {
+		t.Fatalf("newRotateSink: %v", err)
+	}
+	f := sink.(*rotatingFile)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestRotateURL(t *testing.T) {
+	rc := RotationConfig{MaxSizeMB: 10, MaxBackups: 2}
+	for _, path := range []string{"stdout", "stderr", "rotate:/tmp/a.log", "file:///tmp/a.log"} {
+		testutil.Equal(t, rotateURL(path, rc), path)
+	}
+	got := rotateURL("/var/log/app.log", rc)
+	testutil.Equal(t, got, "rotate:/var/log/app.log?compress=false&max_age_days=0&max_backups=2&max_size_mb=10")
+	testutil.Equal(t, rotateURL("/var/log/app.log", RotationConfig{}), "rotate:/var/log/app.log?compress=false&max_age_days=0&max_backups=0&max_size_mb=0")
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f := openRotateSink(t, "rotate:"+filepath.ToSlash(path)+"?max_size_mb=1&max_backups=1")
+
+	line := strings.Repeat("x", 1023) + "\n"
+	for i := 0; i < 1536; i++ {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1", len(backups))
+	}
+	// 切割只发生在两次 Write 之间，每个文件都以完整的行结束
+	for _, name := range []string{path, filepath.Join(dir, backups[0].Name())} {
+		got := readFile(t, name)
+		if len(got) > 1024*1024 || !strings.HasSuffix(got, "\n") {
+			t.Errorf("%s: size %d, ends with newline %v", name, len(got), strings.HasSuffix(got, "\n"))
+		}
+	}
+}
+
+func TestRotatingFileWithoutMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f := openRotateSink(t, "rotate:"+filepath.ToSlash(path))
+
+	chunk := make([]byte, 512*1024)
+	for i := 0; i < 4; i++ {
+		if _, err := f.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := f.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.Equal(t, len(backups), 0)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testutil.Equal(t, info.Size(), int64(2*1024*1024))
+}
@@ -0,0 +1,169 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	DropReasonSampling  = "sampling"
+	DropReasonRateLimit = "ratelimit"
+	DropReasonOverflow  = "overflow"
+)
+
+// SamplingConfig 按 (level, message) 采样：每秒前 Initial 条全部输出，之后每 Thereafter 条输出一条；
+// Initial/Thereafter 为 0 时使用 100
+type SamplingConfig struct {
+	Disabled   bool `mapstructure:"disabled" yaml:"disabled"`
+	Initial    int  `mapstructure:"initial" yaml:"initial" default:"100" validate:"min=0"`
+	Thereafter int  `mapstructure:"thereafter" yaml:"thereafter" default:"100" validate:"min=0"`
+}
+
+// wrap 在 core 外包一层采样器，Disabled 时原样返回
+func (c SamplingConfig) wrap(core zapcore.Core) zapcore.Core {
+	if c.Disabled {
+		return core
+	}
+	initial, thereafter := c.Initial, c.Thereafter
+	if initial == 0 {
+		initial = 100
+	}
+	if thereafter == 0 {
+		thereafter = 100
+	}
+	return zapcore.NewSamplerWithOptions(core, time.Second, initial, thereafter,
+		zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				reportDrop(DropReasonSampling, ent.Level)
+			}
+		}),
+	)
+}
+
+// DropReporter 在日志条目被丢弃时回调，reason 为 DropReason* 之一；overflow 丢弃时 level 为空
+type DropReporter func(reason, level string)
+
+var dropReporter atomic.Pointer[DropReporter]
+
+// SetDropReporter 设置丢弃回调，可与日志输出并发调用；传入 nil 取消上报
+func SetDropReporter(r DropReporter) {
+	if r == nil {
+		dropReporter.Store(nil)
+		return
+	}
+	dropReporter.Store(&r)
+}
+
+func reportDrop(reason string, lvl zapcore.Level) {
+	if r := dropReporter.Load(); r != nil {
+		(*r)(reason, lvl.String())
+	}
+}
+
+var sites sync.Map // map[siteKey]*siteLimiter
+
+type siteKey struct {
+	pc    uintptr
+	every time.Duration
+}
+
+// siteLimiter 限制单个调用点的输出频率，被抑制的条数附加到下一条输出的 suppressed 字段；
+// 同时缓存该调用点的限速 logger，避免每次调用都重新构建
+type siteLimiter struct {
+	mu         sync.Mutex
+	every      time.Duration
+	last       time.Time
+	suppressed int
+	cached     cachedLoggerRef
+}
+
+func (l *siteLimiter) allow() (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if !l.last.IsZero() && now.Sub(l.last) < l.every {
+		l.suppressed++
+		return false, 0
+	}
+	l.last = now
+	n := l.suppressed
+	l.suppressed = 0
+	return true, n
+}
+
+func (l *siteLimiter) logger(fields []zapcore.Field) func(base *zap.Logger) *zap.Logger {
+	return func(base *zap.Logger) *zap.Logger {
+		return base.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return &limitedCore{Core: c, lim: l, fields: fields}
+		}))
+	}
+}
+
+// Limited 返回按调用点限速的 logger：同一行代码在 every 内最多输出一条，适合热路径上的告警/错误日志
+//
+//	applog.Limited(ctx, time.Second).Warn("queue full")
+//
+// 返回的 logger 按 (调用点, ctx) 缓存；ctx 上的字段只在条目通过限速后才编码，
+// 而对返回值调用 With 附加的字段会立即编码
+func Limited(ctx context.Context, every time.Duration) *zap.Logger {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	key := siteKey{pc: pcs[0], every: every}
+	v, ok := sites.Load(key)
+	if !ok {
+		v, _ = sites.LoadOrStore(key, &siteLimiter{every: every})
+	}
+	lim := v.(*siteLimiter)
+	f := fieldsFrom(ctx)
+	if f == nil {
+		return lim.cached.get(lim.logger(nil))
+	}
+	ref, ok := f.limited.Load(lim)
+	if !ok {
+		ref, _ = f.limited.LoadOrStore(lim, &cachedLoggerRef{})
+	}
+	return ref.(*cachedLoggerRef).get(lim.logger(f.fields))
+}
+
+// limitedCore 在 Check 时限速，fields 为尚未附加的 ctx 字段
+type limitedCore struct {
+	zapcore.Core
+	lim    *siteLimiter
+	fields []zapcore.Field
+}
+
+func (c *limitedCore) With(fields []zapcore.Field) zapcore.Core {
+	all := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	all = append(all, c.fields...)
+	all = append(all, fields...)
+	return &limitedCore{Core: c.Core.With(all), lim: c.lim}
+}
+
+func (c *limitedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(ent.Level) {
+		return ce
+	}
+	// 先按模块级别过滤，被级别屏蔽的条目不占用限速额度
+	if _, ok := c.Core.(*levelCore); ok && ent.Level < levels.levelFor(ent.LoggerName) {
+		return ce
+	}
+	ok, suppressed := c.lim.allow()
+	if !ok {
+		reportDrop(DropReasonRateLimit, ent.Level)
+		return ce
+	}
+	fields := c.fields
+	if suppressed > 0 {
+		fields = append(fields[:len(fields):len(fields)], zap.Int("suppressed", suppressed))
+	}
+	if len(fields) == 0 {
+		return c.Core.Check(ent, ce)
+	}
+	return c.Core.With(fields).Check(ent, ce)
+}
@@ -0,0 +1,138 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observe 将全局 logger 替换为 observer，测试结束时恢复
+func observe(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zap.DebugLevel)
+	t.Cleanup(zap.ReplaceGlobals(zap.New(core)))
+	return logs
+}
+
+// limitedSite 作为测试中固定的调用点，禁止内联以保证 pc 不变
+//
+//go:noinline
+func limitedSite(ctx context.Context, every time.Duration) *zap.Logger {
+	return Limited(ctx, every)
+}
+
+func TestLimited(t *testing.T) {
+	logs := observe(t)
+	var dropped int
+	SetDropReporter(func(reason, level string) {
+		if reason == DropReasonRateLimit {
+			dropped++
+		}
+	})
+	t.Cleanup(func() { SetDropReporter(nil) })
+
+	ctx := WithTraceID(context.Background(), "abc")
+	every := 50 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		limitedSite(ctx, every).With(zap.Int("i", i)).Warn("queue full")
+	}
+	testutil.Equal(t, logs.Len(), 1)
+	testutil.Equal(t, dropped, 4)
+
+	time.Sleep(every)
+	for i := 5; i < 7; i++ {
+		limitedSite(ctx, every).With(zap.Int("i", i)).Warn("queue full")
+	}
+	all := logs.All()
+	testutil.Equal(t, len(all), 2)
+	testutil.Equal(t, all[0].ContextMap(), map[string]any{"trace_id": "abc", "i": int64(0)})
+	testutil.Equal(t, all[1].ContextMap(), map[string]any{"trace_id": "abc", "i": int64(5), "suppressed": int64(4)})
+	// ctx 字段在 With 字段之前
+	testutil.Equal(t, all[1].Context[0].Key, "trace_id")
+}
+
+func TestLimitedCachesLoggerPerSite(t *testing.T) {
+	observe(t)
+	site := limitedSite
+	first := site(context.Background(), time.Second)
+	if site(context.Background(), time.Second) != first {
+		t.Error("Limited rebuilt the logger for the same call site")
+	}
+	if site(context.Background(), time.Minute) == first {
+		t.Error("call sites with different intervals share a logger")
+	}
+	// 全局 logger 替换后重建
+	observe(t)
+	if site(context.Background(), time.Second) == first {
+		t.Error("Limited kept the logger of the replaced global")
+	}
+}
+
+func TestLimitedSitesAreIndependent(t *testing.T) {
+	logs := observe(t)
+	for i := 0; i < 3; i++ {
+		Limited(nil, time.Minute).Info("a")
+		Limited(nil, time.Minute).Info("b")
+	}
+	testutil.Equal(t, logs.FilterMessage("a").Len(), 1)
+	testutil.Equal(t, logs.FilterMessage("b").Len(), 1)
+}
+
+func TestLimitedCachesLoggerPerContext(t *testing.T) {
+	observe(t)
+	ctx := WithTraceID(context.Background(), "abc")
+	first := limitedSite(ctx, 2*time.Second)
+	if limitedSite(ctx, 2*time.Second) != first {
+		t.Error("Limited rebuilt the logger for the same ctx")
+	}
+	if limitedSite(WithTraceID(context.Background(), "def"), 2*time.Second) == first {
+		t.Error("contexts with different fields share a logger")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		limitedSite(ctx, 2*time.Second)
+	})
+	testutil.Equal(t, allocs, float64(0))
+}
+
+func TestLimitedChecksLevelFirst(t *testing.T) {
+	logs := observeLevels(t)
+	if err := SetLoggerLevel("pool", "error"); err != nil {
+		t.Fatal(err)
+	}
+	var dropped int
+	SetDropReporter(func(reason, _ string) {
+		if reason == DropReasonRateLimit {
+			dropped++
+		}
+	})
+	t.Cleanup(func() { SetDropReporter(nil) })
+
+	// 被模块级别屏蔽的条目不消耗额度，随后的条目仍能输出
+	every := 3 * time.Second
+	limitedSite(nil, every).Named("pool").Warn("filtered")
+	limitedSite(nil, every).Named("api").Warn("kept")
+	testutil.Equal(t, logs.Len(), 1)
+	testutil.Equal(t, logs.All()[0].Message, "kept")
+	testutil.Equal(t, dropped, 0)
+}
+
+func TestSetDropReporterConcurrently(t *testing.T) {
+	observe(t)
+	t.Cleanup(func() { SetDropReporter(nil) })
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetDropReporter(func(string, string) {})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		limitedSite(nil, time.Hour).Warn("busy")
+	}
+	<-done
+}
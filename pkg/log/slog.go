@@ -0,0 +1,171 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler 将 log/slog 的记录写入全局 zap core，与 zap 日志共享编码、级别、输出与脱敏配置；
+// ctx 上通过 WithFields/WithTraceID 附加的字段（含 trace_id）同样会输出
+type slogHandler struct {
+	attrs  []zap.Field // WithGroup 之前添加的顶层字段
+	groups []slogGroup
+}
+
+type slogGroup struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// NewSlogHandler 返回基于全局 zap logger 的 slog.Handler，Init 替换 logger 后自动跟随
+func NewSlogHandler() slog.Handler {
+	return &slogHandler{}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return zap.L().Core().Enabled(zapLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   zapLevel(r.Level),
+		Time:    r.Time,
+		Message: r.Message,
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+	}
+	ce := zap.L().Core().Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+
+	ctxFields := FieldsFromContext(ctx)
+	fields := make([]zap.Field, 0, len(ctxFields)+len(h.attrs)+r.NumAttrs())
+	fields = append(fields, ctxFields...)
+	fields = append(fields, h.attrs...)
+	var recAttrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		recAttrs = append(recAttrs, a)
+		return true
+	})
+	if len(h.groups) == 0 {
+		fields = appendAttrs(fields, recAttrs)
+	} else {
+		fields = append(fields, h.nest(recAttrs)...)
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+// nest 将已打开的 group 与记录属性由内向外组装为嵌套对象
+func (h *slogHandler) nest(attrs []slog.Attr) []zap.Field {
+	inner := attrs
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		all := make([]slog.Attr, 0, len(g.attrs)+len(inner))
+		all = append(all, g.attrs...)
+		all = append(all, inner...)
+		inner = nil
+		if len(all) > 0 {
+			inner = []slog.Attr{{Key: g.name, Value: slog.GroupValue(all...)}}
+		}
+	}
+	return appendAttrs(nil, inner)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := &slogHandler{attrs: h.attrs, groups: append([]slogGroup(nil), h.groups...)}
+	if len(clone.groups) == 0 {
+		clone.attrs = appendAttrs(append([]zap.Field(nil), h.attrs...), attrs)
+		return clone
+	}
+	last := &clone.groups[len(clone.groups)-1]
+	last.attrs = append(append([]slog.Attr(nil), last.attrs...), attrs...)
+	return clone
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]slogGroup, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	return &slogHandler{attrs: h.attrs, groups: append(groups, slogGroup{name: name})}
+}
+
+func zapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case l < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case l < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func appendAttrs(fields []zap.Field, attrs []slog.Attr) []zap.Field {
+	for _, a := range attrs {
+		if f, ok := attrField(a); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func attrField(a slog.Attr) (zap.Field, bool) {
+	v := a.Value.Resolve()
+	if a.Key == "" && v.Kind() != slog.KindGroup {
+		return zap.Field{}, false
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return zap.String(a.Key, v.String()), true
+	case slog.KindInt64:
+		return zap.Int64(a.Key, v.Int64()), true
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, v.Uint64()), true
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, v.Float64()), true
+	case slog.KindBool:
+		return zap.Bool(a.Key, v.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(a.Key, v.Duration()), true
+	case slog.KindTime:
+		return zap.Time(a.Key, v.Time()), true
+	case slog.KindGroup:
+		group := v.Group()
+		if len(group) == 0 {
+			return zap.Field{}, false
+		}
+		if a.Key == "" {
+			// 按 slog 约定，匿名 group 的属性内联到上一层
+			return zap.Inline(attrObject(group)), true
+		}
+		return zap.Object(a.Key, attrObject(group)), true
+	default:
+		return zap.Any(a.Key, v.Any()), true
+	}
+}
+
+type attrObject []slog.Attr
+
+func (o attrObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, a := range o {
+		if f, ok := attrField(a); ok {
+			f.AddTo(enc)
+		}
+	}
+	return nil
+}
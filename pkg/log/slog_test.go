@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	stdlog "log"
+	"log/slog"
+	"testing"
+
+	"mini-jupiter/internal/testutil"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlogHandler(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	t.Cleanup(zap.ReplaceGlobals(zap.New(core)))
+	sl := slog.New(NewSlogHandler())
+	ctx := WithTraceID(context.Background(), "abc")
+
+	sl.DebugContext(ctx, "hidden")
+	sl.InfoContext(ctx, "info", "n", 1, "ok", true)
+	sl.Log(ctx, slog.LevelWarn+1, "warn+1")
+	sl.With("user", "u1").WithGroup("req").With("method", "GET").
+		ErrorContext(ctx, "failed", slog.Group("", "inline", 1), slog.Group("empty"), "", "no key")
+
+	all := logs.All()
+	testutil.Equal(t, len(all), 3)
+
+	testutil.Equal(t, all[0].Level, zapcore.InfoLevel)
+	testutil.Equal(t, all[0].ContextMap(), map[string]any{"trace_id": "abc", "n": int64(1), "ok": true})
+	if !all[0].Caller.Defined || all[0].Caller.TrimmedPath() == "" {
+		t.Errorf("caller not set: %+v", all[0].Caller)
+	}
+
+	testutil.Equal(t, all[1].Level, zapcore.WarnLevel)
+
+	testutil.Equal(t, all[2].Level, zapcore.ErrorLevel)
+	testutil.Equal(t, all[2].ContextMap(), map[string]any{
+		"trace_id": "abc",
+		"user":     "u1",
+		"req":      map[string]any{"method": "GET", "inline": int64(1)},
+	})
+}
+
+func TestInitRoutesSlogAndStdlog(t *testing.T) {
+	initForTest(t, Config{Level: "debug", OutputPaths: []string{"stderr"}})
+	// Init 之后替换为 observer：slog 默认 handler 跟随全局 logger
+	core, logs := observer.New(zapcore.DebugLevel)
+	zap.ReplaceGlobals(zap.New(core))
+
+	slog.Info("from slog", "k", "v")
+	stdlog.Print("from stdlog")
+
+	testutil.Equal(t, logs.FilterMessage("from slog").FilterField(zap.String("k", "v")).Len(), 1)
+	testutil.Equal(t, logs.FilterMessage("from stdlog").Len(), 1)
+}
@@ -1,6 +1,7 @@
 package metric
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,8 +11,8 @@ import (
 
 type Config struct {
 	Enabled   bool   `mapstructure:"enabled" yaml:"enabled"`
-	Path      string `mapstructure:"path" yaml:"path"`
-	Namespace string `mapstructure:"namespace" yaml:"namespace"`
+	Path      string `mapstructure:"path" yaml:"path" default:"/metrics"`
+	Namespace string `mapstructure:"namespace" yaml:"namespace" default:"mini_jupiter"`
 }
 
 type Metrics struct {
@@ -19,13 +20,23 @@ type Metrics struct {
 	reqLatency *prometheus.HistogramVec
 	inFlight   *prometheus.GaugeVec
 	errCount   *prometheus.CounterVec
+
+	cfgReloads    *prometheus.CounterVec
+	cfgLastReload prometheus.Gauge
+	cfgInfo       *prometheus.GaugeVec
+
+	logDropped *prometheus.CounterVec
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Path == "" {
+		return errors.New("path is required when metric is enabled")
+	}
+	return nil
 }
 
 func New(cfg Config) *Metrics {
 	ns := cfg.Namespace
-	if ns == "" {
-		ns = "mini_jupiter"
-	}
 	m := &Metrics{
 		reqCount: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -60,8 +71,40 @@ func New(cfg Config) *Metrics {
 			},
 			[]string{"code"},
 		),
+		cfgReloads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: ns,
+				Name:      "config_reload_total",
+				Help:      "Total number of config loads by result.",
+			},
+			[]string{"result"},
+		),
+		cfgLastReload: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: ns,
+				Name:      "config_last_reload_success_timestamp_seconds",
+				Help:      "Unix timestamp of the last successful config load.",
+			},
+		),
+		cfgInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: ns,
+				Name:      "config_info",
+				Help:      "Hash of the config currently in effect, value is always 1.",
+			},
+			[]string{"hash"},
+		),
+		logDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: ns,
+				Name:      "log_dropped_total",
+				Help:      "Total number of log entries dropped by sampling or rate limiting.",
+			},
+			[]string{"reason", "level"},
+		),
 	}
-	prometheus.MustRegister(m.reqCount, m.reqLatency, m.inFlight, m.errCount)
+	prometheus.MustRegister(m.reqCount, m.reqLatency, m.inFlight, m.errCount,
+		m.cfgReloads, m.cfgLastReload, m.cfgInfo, m.logDropped)
 	return m
 }
 
@@ -110,3 +153,30 @@ func (m *Metrics) ObserveError(code int) {
 		"code": strconv.Itoa(code),
 	}).Inc()
 }
+
+func (m *Metrics) ObserveConfigReload(success bool, hash string) {
+	if m == nil {
+		return
+	}
+	if !success {
+		m.cfgReloads.With(prometheus.Labels{"result": "failure"}).Inc()
+		return
+	}
+	m.cfgReloads.With(prometheus.Labels{"result": "success"}).Inc()
+	m.cfgLastReload.SetToCurrentTime()
+	if len(hash) > 16 {
+		hash = hash[:16]
+	}
+	m.cfgInfo.Reset()
+	m.cfgInfo.With(prometheus.Labels{"hash": hash}).Set(1)
+}
+
+func (m *Metrics) ObserveLogDropped(reason, level string) {
+	if m == nil {
+		return
+	}
+	m.logDropped.With(prometheus.Labels{
+		"reason": reason,
+		"level":  level,
+	}).Inc()
+}
@@ -0,0 +1,125 @@
+package metric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// New 注册到默认 registry，同一进程只能创建一次
+var testMetrics = New(Config{Enabled: true, Path: "/metrics", Namespace: "metric_test"})
+
+// value 返回默认 registry 中 name 指标在 labels 完全匹配时的值，不存在返回 -1
+func value(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			if matchLabels(m, labels) {
+				switch {
+				case m.Counter != nil:
+					return m.Counter.GetValue()
+				case m.Gauge != nil:
+					return m.Gauge.GetValue()
+				case m.Histogram != nil:
+					return float64(m.Histogram.GetSampleCount())
+				}
+			}
+		}
+	}
+	return -1
+}
+
+func matchLabels(m *dto.Metric, labels map[string]string) bool {
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, l := range m.GetLabel() {
+		if labels[l.GetName()] != l.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestObserveConfigReload(t *testing.T) {
+	testMetrics.ObserveConfigReload(true, "0123456789abcdef0123")
+	testMetrics.ObserveConfigReload(false, "0123456789abcdef0123")
+	testMetrics.ObserveConfigReload(true, "fedcba9876543210ffff")
+
+	if got := value(t, "metric_test_config_reload_total", map[string]string{"result": "success"}); got != 2 {
+		t.Errorf("success reloads = %v, want 2", got)
+	}
+	if got := value(t, "metric_test_config_reload_total", map[string]string{"result": "failure"}); got != 1 {
+		t.Errorf("failed reloads = %v, want 1", got)
+	}
+	// config_info 只保留当前生效配置的摘要（截断为 16 位）
+	if got := value(t, "metric_test_config_info", map[string]string{"hash": "fedcba9876543210"}); got != 1 {
+		t.Errorf("config_info for current hash = %v, want 1", got)
+	}
+	if got := value(t, "metric_test_config_info", map[string]string{"hash": "0123456789abcdef"}); got != -1 {
+		t.Errorf("config_info still reports the previous hash")
+	}
+	if got := value(t, "metric_test_config_last_reload_success_timestamp_seconds", nil); got <= 0 {
+		t.Errorf("last reload timestamp = %v", got)
+	}
+}
+
+func TestObserveRequestsAndErrors(t *testing.T) {
+	testMetrics.IncInFlight("GET", "/ping")
+	testMetrics.Observe("GET", "/ping", 200, 0.01)
+	testMetrics.DecInFlight("GET", "/ping")
+	testMetrics.ObserveError(429)
+	testMetrics.ObserveLogDropped("sampling", "info")
+	testMetrics.ObserveLogDropped("sampling", "info")
+
+	labels := map[string]string{"method": "GET", "path": "/ping", "status": "200"}
+	if got := value(t, "metric_test_http_requests_total", labels); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+	if got := value(t, "metric_test_http_request_duration_seconds", labels); got != 1 {
+		t.Errorf("latency samples = %v, want 1", got)
+	}
+	if got := value(t, "metric_test_http_inflight_requests", map[string]string{"method": "GET", "path": "/ping"}); got != 0 {
+		t.Errorf("in-flight = %v, want 0", got)
+	}
+	if got := value(t, "metric_test_http_error_total", map[string]string{"code": "429"}); got != 1 {
+		t.Errorf("errors = %v, want 1", got)
+	}
+	if got := value(t, "metric_test_log_dropped_total", map[string]string{"reason": "sampling", "level": "info"}); got != 2 {
+		t.Errorf("dropped logs = %v, want 2", got)
+	}
+
+	// nil *Metrics 上的调用均为空操作
+	var nilMetrics *Metrics
+	nilMetrics.Observe("GET", "/", 200, 0)
+	nilMetrics.ObserveConfigReload(true, "")
+	nilMetrics.ObserveLogDropped("sampling", "info")
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	testMetrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "metric_test_config_info") {
+		t.Errorf("unexpected /metrics response %d", rec.Code)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (Config{Enabled: true}).Validate(); err == nil {
+		t.Error("Validate accepted an enabled config without path")
+	}
+	if err := (Config{}).Validate(); err != nil {
+		t.Errorf("Validate(disabled) = %v", err)
+	}
+}
@@ -5,15 +5,25 @@ import (
 	"errors"
 	"sync"
 	"time"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
 )
 
 var ErrClosed = errors.New("worker pool closed")
 
 type Task func(context.Context) error
 
+// job 记录提交时的 ctx，任务执行时继承其中的值（trace_id、日志字段等），但不继承取消
+type job struct {
+	ctx  context.Context
+	task Task
+}
+
 type Pool struct {
 	workers     int
-	tasks       chan Task
+	tasks       chan job
 	wg          sync.WaitGroup
 	mu          sync.Mutex
 	closed      bool
@@ -25,7 +35,7 @@ type Option func(*Pool)
 func New(workers int, opts ...Option) *Pool {
 	p := &Pool{
 		workers:     workers,
-		tasks:       make(chan Task, 1024),
+		tasks:       make(chan job, 1024),
 		taskTimeout: 10 * time.Second,
 	}
 	for _, opt := range opts {
@@ -35,13 +45,18 @@ func New(workers int, opts ...Option) *Pool {
 		p.wg.Add(1)
 		go p.worker()
 	}
+	applog.Named("pool").Debug("pool started",
+		zap.Int("workers", p.workers),
+		zap.Int("buffer", cap(p.tasks)),
+		zap.Duration("task_timeout", p.taskTimeout),
+	)
 	return p
 }
 
 func WithBuffer(size int) Option {
 	return func(p *Pool) {
 		if size > 0 {
-			p.tasks = make(chan Task, size)
+			p.tasks = make(chan job, size)
 		}
 	}
 }
@@ -66,7 +81,7 @@ func (p *Pool) Submit(ctx context.Context, task Task) error {
 	p.mu.Unlock()
 
 	select {
-	case p.tasks <- task:
+	case p.tasks <- job{ctx: ctx, task: task}:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
@@ -83,13 +98,20 @@ func (p *Pool) Close() {
 	close(p.tasks)
 	p.mu.Unlock()
 	p.wg.Wait()
+	applog.Named("pool").Debug("pool closed")
 }
 
 func (p *Pool) worker() {
 	defer p.wg.Done()
-	for task := range p.tasks {
-		ctx, cancel := context.WithTimeout(context.Background(), p.taskTimeout)
-		_ = task(ctx)
+	for j := range p.tasks {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(j.ctx), p.taskTimeout)
+		start := time.Now()
+		if err := j.task(ctx); err != nil {
+			applog.NamedL(ctx, "pool").Debug("task failed",
+				zap.Duration("cost", time.Since(start)),
+				zap.Error(err),
+			)
+		}
 		cancel()
 	}
 }
@@ -0,0 +1,46 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	applog "mini-jupiter/pkg/log"
+	"mini-jupiter/pkg/log/logtest"
+
+	"go.uber.org/zap"
+)
+
+func TestTaskInheritsContextValues(t *testing.T) {
+	logs := logtest.New(t)
+	p := New(1, WithTaskTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(applog.WithTraceID(context.Background(), "abc"))
+	ctx = applog.WithFields(ctx, zap.String("user", "u1"))
+	done := make(chan error, 1)
+	if err := p.Submit(ctx, func(ctx context.Context) error {
+		applog.L(ctx).Info("in task")
+		done <- ctx.Err()
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	// 提交方取消不影响已入队的任务
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("task ctx err = %v", err)
+	}
+	p.Close()
+
+	logs.AssertLogged(logtest.Message("in task"), logtest.TraceID("abc"), logtest.Field("user", "u1"))
+	logs.AssertLogged(logtest.Logger("pool"), logtest.Message("task failed"), logtest.TraceID("abc"), logtest.Field("error", "boom"))
+}
+
+func TestSubmitAfterClose(t *testing.T) {
+	p := New(1)
+	p.Close()
+	if err := p.Submit(context.Background(), func(context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Close = %v, want ErrClosed", err)
+	}
+}
@@ -1,14 +1,22 @@
 package ratelimiter
 
 import (
+	"errors"
 	"sync"
 	"time"
 )
 
 type Config struct {
 	Enabled bool    `mapstructure:"enabled" yaml:"enabled"`
-	Rate    float64 `mapstructure:"rate" yaml:"rate"`
-	Burst   int     `mapstructure:"burst" yaml:"burst"`
+	Rate    float64 `mapstructure:"rate" yaml:"rate" default:"1" validate:"min=0"`
+	Burst   int     `mapstructure:"burst" yaml:"burst" default:"1" validate:"min=1"`
+}
+
+func (c Config) Validate() error {
+	if c.Enabled && c.Rate <= 0 {
+		return errors.New("rate must be > 0 when ratelimit is enabled")
+	}
+	return nil
 }
 
 type Limiter struct {
@@ -19,13 +27,10 @@ type Limiter struct {
 	mu     sync.Mutex
 }
 
+// New 按每秒 rate 个令牌、容量 burst 创建限流器；配置的默认值由 Config 的标签提供，
+// 直接调用时非正的 rate/burst 按标签默认值 1 处理
 func New(rate float64, burst int) *Limiter {
-	if rate <= 0 {
-		rate = 1
-	}
-	if burst <= 0 {
-		burst = 1
-	}
+	rate, burst = clamp(rate, burst)
 	now := time.Now()
 	return &Limiter{
 		rate:   rate,
@@ -35,6 +40,28 @@ func New(rate float64, burst int) *Limiter {
 	}
 }
 
+// Update 在运行时调整速率与桶容量，已积累的令牌按新容量截断
+func (l *Limiter) Update(rate float64, burst int) {
+	rate, burst = clamp(rate, burst)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.burst = float64(burst)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+func clamp(rate float64, burst int) (float64, int) {
+	if rate <= 0 {
+		rate = 1
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return rate, burst
+}
+
 func (l *Limiter) Allow() bool {
 	l.mu.Lock()
 	defer l.mu.Unlock()
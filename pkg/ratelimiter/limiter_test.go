@@ -0,0 +1,74 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowBurst(t *testing.T) {
+	l := New(1, 3)
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Fatalf("request %d rejected within burst", i)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("request allowed after burst was used up")
+	}
+}
+
+func TestAllowRefill(t *testing.T) {
+	l := New(100, 1)
+	if !l.Allow() {
+		t.Fatal("first request rejected")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !l.Allow() {
+		t.Fatal("request rejected after refill")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	l := New(1, 5)
+	l.Update(1, 2)
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if l.Allow() {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Fatalf("allowed %d requests after shrinking burst to 2", allowed)
+	}
+}
+
+func TestNonPositiveInputs(t *testing.T) {
+	// 非正的 rate/burst 按 1 处理，而不是永远拒绝
+	l := New(0, 0)
+	if !l.Allow() {
+		t.Fatal("New(0, 0) rejected the first request")
+	}
+	if l.Allow() {
+		t.Fatal("New(0, 0) allowed a burst above 1")
+	}
+	l.Update(-1, -1)
+	if l.rate != 1 || l.burst != 1 {
+		t.Fatalf("Update(-1, -1) = rate %v burst %v, want 1 and 1", l.rate, l.burst)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     Config
+		wantErr bool
+	}{
+		{Config{Enabled: true, Rate: 1, Burst: 1}, false},
+		{Config{Enabled: true, Rate: 0, Burst: 1}, true},
+		{Config{Enabled: false, Rate: 0}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
@@ -3,8 +3,13 @@ package runtime
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
+
+	applog "mini-jupiter/pkg/log"
+
+	"go.uber.org/zap"
 )
 
 type App struct {
@@ -43,20 +48,31 @@ func (a *App) Start(ctx context.Context) error {
 	var started []Component
 	for _, c := range a.components {
 		if err := c.Start(ctx); err != nil {
+			applog.Named("runtime").Error("component start failed",
+				zap.String("component", componentName(c)),
+				zap.Error(err),
+			)
 			_ = a.stopReverse(ctx, started)
 			return err
 		}
+		applog.Named("runtime").Debug("component started",
+			zap.String("component", componentName(c)),
+		)
 		started = append(started, c)
 	}
 	return nil
 }
 
+// Stop 逆序停止所有组件，最后刷新日志（包括异步 writer 中尚未写出的条目）
 func (a *App) Stop(ctx context.Context) error {
-	return a.stopReverse(ctx, a.components)
+	err := a.stopReverse(ctx, a.components)
+	applog.Sync()
+	return err
 }
 
 func (a *App) stopReverse(ctx context.Context, comps []Component) error {
-	ctx = withDefaultTimeout(ctx, a.stopTimeout)
+	ctx, cancel := withDefaultTimeout(ctx, a.stopTimeout)
+	defer cancel()
 	var (
 		wg  sync.WaitGroup
 		mu  sync.Mutex
@@ -68,10 +84,18 @@ func (a *App) stopReverse(ctx context.Context, comps []Component) error {
 		go func() {
 			defer wg.Done()
 			if err := c.Stop(ctx); err != nil {
+				applog.Named("runtime").Error("component stop failed",
+					zap.String("component", componentName(c)),
+					zap.Error(err),
+				)
 				mu.Lock()
 				errs = append(errs, err)
 				mu.Unlock()
+				return
 			}
+			applog.Named("runtime").Debug("component stopped",
+				zap.String("component", componentName(c)),
+			)
 		}()
 	}
 	wg.Wait()
@@ -81,13 +105,16 @@ func (a *App) stopReverse(ctx context.Context, comps []Component) error {
 	return nil
 }
 
-func withDefaultTimeout(ctx context.Context, d time.Duration) context.Context {
+func componentName(c Component) string {
+	return fmt.Sprintf("%T", c)
+}
+
+func withDefaultTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	if _, ok := ctx.Deadline(); ok {
-		return ctx
+		return ctx, func() {}
 	}
-	c, _ := context.WithTimeout(ctx, d)
-	return c
+	return context.WithTimeout(ctx, d)
 }